@@ -0,0 +1,425 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/user"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// auditLimit bounds how many entries the history view loads.
+const auditLimit = 500
+
+// auditEntry is one recorded action.
+type auditEntry struct {
+	at       time.Time
+	user     string
+	unit     string
+	action   string
+	exit     int
+	stderr   string
+	duration time.Duration
+}
+
+// auditingManager records every action of the wrapped manager in the audit
+// log. Read-only calls pass through untouched.
+type auditingManager struct {
+	ServiceManager
+	db   *sql.DB
+	user string
+}
+
+func newAuditingManager(manager ServiceManager, db *sql.DB) auditingManager {
+	return auditingManager{ServiceManager: manager, db: db, user: invokingUser()}
+}
+
+// invokingUser is who started lazysys, looking through sudo.
+func invokingUser() string {
+	for _, env := range []string{"SUDO_USER", "USER"} {
+		if name := os.Getenv(env); name != "" {
+			return name
+		}
+	}
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return strconv.Itoa(os.Getuid())
+}
+
+// record stores the outcome of action with whatever it printed. A failing
+// insert must not turn a successful action into an error, so it is dropped.
+func (m auditingManager) record(unit, action string, start time.Time, output string, err error) error {
+	entry := auditEntry{at: start, user: m.user, unit: unit, action: action, duration: time.Since(start), stderr: output}
+	if err != nil {
+		entry.exit, entry.stderr = 1, strings.TrimSpace(err.Error()+"\n"+output)
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			entry.exit = exitErr.ExitCode()
+		}
+	}
+	insertAuditEntry(m.db, entry)
+	return err
+}
+
+func (m auditingManager) audit(unit, action string, fn func() error) error {
+	start := time.Now()
+	return m.record(unit, action, start, "", fn())
+}
+
+// auditAction runs an action that reaches the system without going through
+// the manager, like writing a unit file or signalling a single process, and
+// records it when manager keeps an audit log.
+func auditAction(manager ServiceManager, unit, action string, fn func() (string, error)) (string, error) {
+	start := time.Now()
+	output, err := fn()
+	if m, ok := manager.(auditingManager); ok {
+		m.record(unit, action, start, output, err)
+	}
+	return output, err
+}
+
+func (m auditingManager) Start(name string) error {
+	return m.audit(name, "start", func() error { return m.ServiceManager.Start(name) })
+}
+
+func (m auditingManager) Stop(name string) error {
+	return m.audit(name, "stop", func() error { return m.ServiceManager.Stop(name) })
+}
+
+func (m auditingManager) Restart(name string) error {
+	return m.audit(name, "restart", func() error { return m.ServiceManager.Restart(name) })
+}
+
+func (m auditingManager) Enable(name string) error {
+	return m.audit(name, "enable", func() error { return m.ServiceManager.Enable(name) })
+}
+
+func (m auditingManager) Disable(name string) error {
+	return m.audit(name, "disable", func() error { return m.ServiceManager.Disable(name) })
+}
+
+func (m auditingManager) Mask(name string) error {
+	return m.audit(name, "mask", func() error { return m.ServiceManager.Mask(name) })
+}
+
+func (m auditingManager) Unmask(name string) error {
+	return m.audit(name, "unmask", func() error { return m.ServiceManager.Unmask(name) })
+}
+
+func (m auditingManager) Reload(name string) error {
+	return m.audit(name, "reload", func() error { return m.ServiceManager.Reload(name) })
+}
+
+func (m auditingManager) TryRestart(name string) error {
+	return m.audit(name, "try-restart", func() error { return m.ServiceManager.TryRestart(name) })
+}
+
+func (m auditingManager) ReloadOrRestart(name string) error {
+	return m.audit(name, "reload-or-restart", func() error { return m.ServiceManager.ReloadOrRestart(name) })
+}
+
+func (m auditingManager) Isolate(name string) error {
+	return m.audit(name, "isolate", func() error { return m.ServiceManager.Isolate(name) })
+}
+
+func (m auditingManager) ResetFailed(name string) error {
+	unit := name
+	if unit == "" {
+		unit = "*"
+	}
+	return m.audit(unit, "reset-failed", func() error { return m.ServiceManager.ResetFailed(name) })
+}
+
+func (m auditingManager) Kill(name string, signal syscall.Signal) error {
+	action := "kill " + strconv.Itoa(int(signal))
+	for _, s := range processSignals {
+		if s.signal == signal {
+			action = "kill " + s.name
+		}
+	}
+	return m.audit(name, action, func() error { return m.ServiceManager.Kill(name, signal) })
+}
+
+func (m auditingManager) DaemonReload() error {
+	return m.audit("", "daemon-reload", m.ServiceManager.DaemonReload)
+}
+
+// WatchUnits keeps the wrapped manager's notifications, which embedding the
+// interface would hide from watchUnits.
+func (m auditingManager) WatchUnits(done <-chan struct{}) (<-chan []unitChange, error) {
+	if w, ok := m.ServiceManager.(unitWatcher); ok {
+		return w.WatchUnits(done)
+	}
+	return nil, errors.New("backend has no unit notifications")
+}
+
+// ListLoadedUnits keeps the wrapped manager's cheaper listing for polling.
+func (m auditingManager) ListLoadedUnits() ([]service, error) {
+	if l, ok := m.ServiceManager.(loadedUnitLister); ok {
+		return l.ListLoadedUnits()
+	}
+	return m.ServiceManager.ListUnits("")
+}
+
+func insertAuditEntry(db *sql.DB, e auditEntry) error {
+	_, err := db.Exec(`INSERT INTO audit_log (timestamp, user, unit, action, exit_status, stderr, duration_ms)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		e.at.UTC().Format(time.RFC3339Nano), e.user, e.unit, e.action, e.exit, e.stderr, e.duration.Milliseconds())
+	return err
+}
+
+// loadAuditEntries returns the newest entries first, those whose unit
+// contains unit and whose action is action when they are set. Kills match
+// whatever their signal.
+func loadAuditEntries(db *sql.DB, unit, action string, limit int) ([]auditEntry, error) {
+	query := "SELECT timestamp, user, unit, action, exit_status, stderr, duration_ms FROM audit_log WHERE 1 = 1"
+	var args []interface{}
+	if unit != "" {
+		query += " AND unit LIKE ?"
+		args = append(args, "%"+unit+"%")
+	}
+	if action != "" {
+		query += " AND (action = ? OR action LIKE ?)"
+		args = append(args, action, action+" %")
+	}
+	query += " ORDER BY id DESC"
+	if limit > 0 {
+		query += " LIMIT " + strconv.Itoa(limit)
+	}
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []auditEntry
+	for rows.Next() {
+		var e auditEntry
+		var at string
+		var durationMS int64
+		if err := rows.Scan(&at, &e.user, &e.unit, &e.action, &e.exit, &e.stderr, &durationMS); err != nil {
+			return nil, err
+		}
+		e.at, _ = time.Parse(time.RFC3339Nano, at)
+		e.duration = time.Duration(durationMS) * time.Millisecond
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// auditActions are the distinct actions in the log, for the action filter.
+// Kills count as one action whatever the signal.
+func auditActions(db *sql.DB) ([]string, error) {
+	rows, err := db.Query("SELECT DISTINCT action FROM audit_log ORDER BY action")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var actions []string
+	for rows.Next() {
+		var action string
+		if err := rows.Scan(&action); err != nil {
+			return nil, err
+		}
+		action, _, _ = strings.Cut(action, " ")
+		if len(actions) == 0 || actions[len(actions)-1] != action {
+			actions = append(actions, action)
+		}
+	}
+	return actions, rows.Err()
+}
+
+// dumpAudit writes the whole log, oldest first, for the -audit flag.
+func dumpAudit(w io.Writer, db *sql.DB) error {
+	entries, err := loadAuditEntries(db, "", "", 0)
+	if err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TIME\tUSER\tUNIT\tACTION\tEXIT\tDURATION\tSTDERR")
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\t%s\t%s\n", e.at.Local().Format(time.DateTime), e.user, e.unit, e.action,
+			e.exit, e.duration, strings.ReplaceAll(e.stderr, "\n", " "))
+	}
+	return tw.Flush()
+}
+
+type auditLoadedMsg struct {
+	entries []auditEntry
+	actions []string
+	err     error
+}
+
+// auditView is the state of the action history opened with `A`.
+type auditView struct {
+	table     table.Model
+	entries   []auditEntry
+	actions   []string
+	unit      string // filters, empty for all
+	action    string
+	unitInput textinput.Model
+	filtering bool
+	loading   bool
+	err       error
+}
+
+func loadAudit(db *sql.DB, unit, action string) tea.Cmd {
+	return func() tea.Msg {
+		entries, err := loadAuditEntries(db, unit, action, auditLimit)
+		if err != nil {
+			return auditLoadedMsg{err: err}
+		}
+		actions, err := auditActions(db)
+		return auditLoadedMsg{entries: entries, actions: actions, err: err}
+	}
+}
+
+func newAuditTable(width, height int) table.Model {
+	return newStyledTable([]table.Column{
+		{Title: "Time", Width: 19},
+		{Title: "User", Width: 10},
+		{Title: "Unit", Width: 30},
+		{Title: "Action", Width: 18},
+		{Title: "Exit", Width: 4},
+		{Title: "Duration", Width: 9},
+		{Title: "Error", Width: max(width-110, 20)},
+	}, width, height)
+}
+
+func (v *auditView) render() {
+	rows := make([]table.Row, 0, len(v.entries))
+	for _, e := range v.entries {
+		unit := e.unit
+		if unit == "" {
+			unit = "-"
+		}
+		rows = append(rows, table.Row{
+			e.at.Local().Format(time.DateTime),
+			e.user,
+			unit,
+			e.action,
+			strconv.Itoa(e.exit),
+			e.duration.Round(time.Millisecond).String(),
+			strings.ReplaceAll(e.stderr, "\n", " "),
+		})
+	}
+	v.table.SetRows(rows)
+}
+
+// openAudit shows the action history, filtered to unit when it is set.
+func (m model) openAudit(unit string) (model, tea.Cmd) {
+	input := textinput.New()
+	input.Placeholder = "part of a unit name"
+	input.Width = 40
+	m.audit = auditView{
+		table:     newAuditTable(m.width, m.height-6),
+		unit:      unit,
+		unitInput: input,
+		loading:   true,
+	}
+	m.showAudit = true
+	return m, loadAudit(m.db, unit, "")
+}
+
+func (m model) updateAudit(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	v := &m.audit
+	if v.filtering {
+		switch msg.String() {
+		case "enter":
+			v.filtering = false
+			v.unit = strings.TrimSpace(v.unitInput.Value())
+			v.unitInput.Blur()
+			v.loading = true
+			return m, loadAudit(m.db, v.unit, v.action)
+		case "esc":
+			v.filtering = false
+			v.unitInput.Blur()
+			return m, nil
+		}
+		var cmd tea.Cmd
+		v.unitInput, cmd = v.unitInput.Update(msg)
+		return m, cmd
+	}
+
+	switch msg.String() {
+	case "q", "esc", "A":
+		m.showAudit = false
+		return m, nil
+	case "ctrl+c":
+		return m, tea.Quit
+	case "u":
+		v.filtering = true
+		v.unitInput.SetValue(v.unit)
+		v.unitInput.CursorEnd()
+		v.unitInput.Focus()
+		return m, textinput.Blink
+	case "a":
+		// Cycle through every action, then back to all of them
+		next := ""
+		if i := slices.Index(v.actions, v.action); i+1 < len(v.actions) {
+			next = v.actions[i+1]
+		}
+		v.action = next
+		v.loading = true
+		return m, loadAudit(m.db, v.unit, v.action)
+	case "c":
+		v.unit, v.action = "", ""
+		v.loading = true
+		return m, loadAudit(m.db, "", "")
+	case "r":
+		v.loading = true
+		return m, loadAudit(m.db, v.unit, v.action)
+	}
+
+	var cmd tea.Cmd
+	v.table, cmd = v.table.Update(msg)
+	return m, cmd
+}
+
+func (m model) auditView() string {
+	v := m.audit
+	status := fmt.Sprintf("%d entries", len(v.entries))
+	if len(v.entries) == auditLimit {
+		status = fmt.Sprintf("newest %d entries", auditLimit)
+	}
+	switch {
+	case v.loading:
+		status = "Loading history..."
+	case v.err != nil:
+		status = fmt.Sprintf("❌ Error loading history: %v", v.err)
+	}
+	filters := []string{}
+	if v.unit != "" {
+		filters = append(filters, "unit ~ "+v.unit)
+	}
+	if v.action != "" {
+		filters = append(filters, "action = "+v.action)
+	}
+	if len(filters) > 0 {
+		status += " | " + strings.Join(filters, ", ")
+	}
+	header := titleStyle.Render("📜 Action history") + " " + helpStyle.Render(status)
+
+	help := helpStyle.Render("j/k: Navigate | u: Filter by unit | a: Cycle action filter | c: Clear filters | r: Reload | q/Esc: Close")
+	if v.filtering {
+		help = "Unit: " + v.unitInput.View() + helpStyle.Render("  Enter: Apply | Esc: Cancel")
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, header, "", v.table.View(), "", help)
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAuditAction(t *testing.T) {
+	db := openTestDB(t)
+	if err := migrate(db); err != nil {
+		t.Fatal(err)
+	}
+	manager := newAuditingManager(newMemoryManager(), db)
+
+	output, err := auditAction(manager, "job.service", "run-transient", func() (string, error) {
+		return "Running as unit: job.service", nil
+	})
+	if output != "Running as unit: job.service" || err != nil {
+		t.Fatalf("auditAction = %q, %v", output, err)
+	}
+	_, err = auditAction(manager, "nginx.service", "edit-override", func() (string, error) {
+		output, err := exec.Command("sh", "-c", "echo 'Unknown key name'; exit 3").CombinedOutput()
+		return strings.TrimSpace(string(output)), err
+	})
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		t.Fatalf("auditAction error %v, want the exit error passed through", err)
+	}
+	if err := manager.Stop("cron.service"); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := loadAuditEntries(db, "", "", auditLimit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("%d entries, want 3", len(entries))
+	}
+	// Newest first
+	stop, override, transient := entries[0], entries[1], entries[2]
+	if stop.unit != "cron.service" || stop.action != "stop" || stop.exit != 0 {
+		t.Errorf("stop recorded as %+v", stop)
+	}
+	if override.unit != "nginx.service" || override.action != "edit-override" || override.exit != 3 || !strings.Contains(override.stderr, "Unknown key name") {
+		t.Errorf("failed override edit recorded as %+v", override)
+	}
+	if transient.unit != "job.service" || transient.exit != 0 || transient.stderr != "Running as unit: job.service" {
+		t.Errorf("transient unit recorded as %+v", transient)
+	}
+}
+
+func TestAuditActionWithoutLog(t *testing.T) {
+	ran := false
+	_, err := auditAction(newMemoryManager(), "cron.service", "create", func() (string, error) {
+		ran = true
+		return "", nil
+	})
+	if !ran || err != nil {
+		t.Errorf("ran %v, err %v: want the action run without an audit log", ran, err)
+	}
+}
+
+func TestDumpAuditReadOnly(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lazysys.db")
+	if _, err := openDatabaseReadOnly(path); err == nil {
+		t.Error("opened a database that does not exist")
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("opening a missing database created it: %v", err)
+	}
+
+	db, err := initDB(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := newAuditingManager(newMemoryManager(), db).Stop("cron.service"); err != nil {
+		t.Fatal(err)
+	}
+	version := schemaVersion(t, db)
+	db.Close()
+
+	db, err = openDatabaseReadOnly(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	var out strings.Builder
+	if err := dumpAudit(&out, db); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "cron.service") || !strings.Contains(out.String(), "stop") {
+		t.Errorf("dump %q lacks the stop of cron.service", out.String())
+	}
+	if _, err := db.Exec("DELETE FROM audit_log"); err == nil {
+		t.Error("wrote to a database opened read-only")
+	}
+	if got := schemaVersion(t, db); got != version {
+		t.Errorf("schema version %d after the dump, want %d", got, version)
+	}
+}
@@ -1,25 +1,119 @@
-
 package main
 
 import (
 	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+
 	_ "github.com/mattn/go-sqlite3"
 )
 
-func initDB() (*sql.DB, error) {
-	db, err := sql.Open("sqlite3", "./lazysys.db")
+// legacyDBPath is where lazysys kept its database before the location became
+// configurable: the directory it was started from.
+const legacyDBPath = "./lazysys.db"
+
+// dbPath resolves the database location: the -db flag, then $LAZYSYS_DB,
+// then /var/lib/lazysys when running as root and $XDG_DATA_HOME/lazysys
+// otherwise.
+func dbPath(flagPath string) (string, error) {
+	if flagPath != "" {
+		return flagPath, nil
+	}
+	if env := os.Getenv("LAZYSYS_DB"); env != "" {
+		return env, nil
+	}
+	if os.Geteuid() == 0 {
+		return "/var/lib/lazysys/lazysys.db", nil
+	}
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dataHome = filepath.Join(home, ".local", "share")
+	}
+	return filepath.Join(dataHome, "lazysys", "lazysys.db"), nil
+}
+
+// importLegacyDB copies ./lazysys.db to path the first time path is used,
+// so notes kept in the old location are not lost. The old file is left
+// alone.
+func importLegacyDB(path string) (bool, error) {
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		return false, err
+	}
+	if info, err := os.Stat(legacyDBPath); err != nil || info.IsDir() {
+		return false, nil
+	}
+
+	db, err := sql.Open("sqlite3", legacyDBPath)
+	if err != nil {
+		return false, err
+	}
+	defer db.Close()
+	// A consistent copy even if another lazysys has the old file open
+	if _, err := db.Exec("VACUUM INTO ?", path); err != nil {
+		return false, fmt.Errorf("importing %s: %w", legacyDBPath, err)
+	}
+	return true, nil
+}
+
+// openDatabase opens the database lazysys should use, importing the legacy
+// one into it on first use.
+func openDatabase(flagPath string) (*sql.DB, error) {
+	path, err := dbPath(flagPath)
 	if err != nil {
 		return nil, err
 	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	imported, err := importLegacyDB(path)
+	if err != nil {
+		return nil, err
+	}
+	if imported {
+		fmt.Fprintf(os.Stderr, "Imported %s into %s, the old file can be removed\n", legacyDBPath, path)
+	}
+	return initDB(path)
+}
 
-	// Create table if not exists
-	_, err = db.Exec(`CREATE TABLE IF NOT EXISTS services (
-		name TEXT PRIMARY KEY,
-		description TEXT
-	)`)
+// openDatabaseReadOnly opens the database for reading only: nothing is
+// created, migrated or imported, so looking at it leaves the disk alone.
+func openDatabaseReadOnly(flagPath string) (*sql.DB, error) {
+	path, err := dbPath(flagPath)
 	if err != nil {
 		return nil, err
 	}
+	if _, err := os.Stat(path); err != nil {
+		return nil, err
+	}
+	dsn := (&url.URL{Scheme: "file", Path: path, RawQuery: "mode=ro"}).String()
+	db, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+func initDB(path string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := migrate(db); err != nil {
+		db.Close()
+		return nil, err
+	}
 
 	return db, nil
 }
@@ -40,3 +134,67 @@ func updateServiceDescription(db *sql.DB, serviceName, description string) error
 	_, err := db.Exec("INSERT OR REPLACE INTO services (name, description) VALUES (?, ?)", serviceName, description)
 	return err
 }
+
+// loadServiceTags returns the tags of every tagged unit, sorted.
+func loadServiceTags(db *sql.DB) (map[string][]string, error) {
+	rows, err := db.Query("SELECT name, tag FROM service_tags ORDER BY name, tag")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := make(map[string][]string)
+	for rows.Next() {
+		var name, tag string
+		if err := rows.Scan(&name, &tag); err != nil {
+			return nil, err
+		}
+		tags[name] = append(tags[name], tag)
+	}
+	return tags, rows.Err()
+}
+
+func updateServiceTags(db *sql.DB, serviceName string, tags []string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM service_tags WHERE name = ?", serviceName); err != nil {
+		return err
+	}
+	for _, tag := range tags {
+		if _, err := tx.Exec("INSERT INTO service_tags (name, tag) VALUES (?, ?)", serviceName, tag); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func loadFavoriteNames(db *sql.DB) (map[string]bool, error) {
+	rows, err := db.Query("SELECT name FROM favorites")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	favorites := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		favorites[name] = true
+	}
+	return favorites, rows.Err()
+}
+
+func updateFavorite(db *sql.DB, serviceName string, favorite bool) error {
+	if favorite {
+		_, err := db.Exec("INSERT OR IGNORE INTO favorites (name) VALUES (?)", serviceName)
+		return err
+	}
+	_, err := db.Exec("DELETE FROM favorites WHERE name = ?", serviceName)
+	return err
+}
@@ -0,0 +1,571 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	systemdBusName      = "org.freedesktop.systemd1"
+	systemdObjectPath   = dbus.ObjectPath("/org/freedesktop/systemd1")
+	systemdManagerIface = "org.freedesktop.systemd1.Manager"
+	systemdUnitIface    = "org.freedesktop.systemd1.Unit"
+)
+
+// dbusUnitStatus mirrors one entry of the Manager.ListUnits reply, a(ssssssouso).
+type dbusUnitStatus struct {
+	Name        string
+	Description string
+	LoadState   string
+	ActiveState string
+	SubState    string
+	Followed    string
+	Path        dbus.ObjectPath
+	JobID       uint32
+	JobType     string
+	JobPath     dbus.ObjectPath
+}
+
+// dbusUnitFile mirrors one entry of the Manager.ListUnitFiles reply, a(ss).
+type dbusUnitFile struct {
+	Path  string
+	State string
+}
+
+// dbusUnitFileChange mirrors one entry of the a(sss) change list returned by
+// EnableUnitFiles and DisableUnitFiles.
+type dbusUnitFileChange struct {
+	Type        string
+	Filename    string
+	Destination string
+}
+
+// dbusManager implements ServiceManager by talking to org.freedesktop.systemd1
+// over the system bus. Jobs are tracked through the JobRemoved signal so that
+// start/stop/restart report the real job result.
+type dbusManager struct {
+	conn    *dbus.Conn
+	systemd dbus.BusObject
+
+	jobsMu     sync.Mutex
+	jobs       map[dbus.ObjectPath]chan string
+	calling    int                        // runJob calls still waiting for their job path
+	early      map[dbus.ObjectPath]string // results of jobs removed during those calls
+	jobTimeout time.Duration
+
+	// Unit signals are only noted here for watchLoop, so that reading unit
+	// states never holds up the JobRemoved signals runJob waits for.
+	watchMu      sync.Mutex
+	watching     bool
+	changedUnits map[dbus.ObjectPath]bool // units to read again
+	removedUnits map[string]bool
+	unitsDirty   chan struct{} // wakes watchLoop, never blocks
+}
+
+// newDBusManager connects to the system bus. DBUS_SYSTEM_BUS_ADDRESS is
+// honoured, which lets the backend run against a private dbus-daemon.
+func newDBusManager() (*dbusManager, error) {
+	conn, err := dbus.ConnectSystemBus()
+	if err != nil {
+		return nil, err
+	}
+	m, err := newDBusManagerWithConn(conn)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return m, nil
+}
+
+func newDBusManagerWithConn(conn *dbus.Conn) (*dbusManager, error) {
+	m := &dbusManager{
+		conn:       conn,
+		systemd:    conn.Object(systemdBusName, systemdObjectPath),
+		jobs:       make(map[dbus.ObjectPath]chan string),
+		early:      make(map[dbus.ObjectPath]string),
+		jobTimeout: jobTimeout,
+
+		changedUnits: make(map[dbus.ObjectPath]bool),
+		removedUnits: make(map[string]bool),
+		unitsDirty:   make(chan struct{}, 1),
+	}
+
+	// systemd only emits job and unit signals to subscribed clients
+	if err := m.systemd.Call(systemdManagerIface+".Subscribe", 0).Err; err != nil {
+		return nil, fmt.Errorf("subscribe to systemd: %w", err)
+	}
+	err := conn.AddMatchSignal(
+		dbus.WithMatchObjectPath(systemdObjectPath),
+		dbus.WithMatchInterface(systemdManagerIface),
+		dbus.WithMatchMember("JobRemoved"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	signals := make(chan *dbus.Signal, 64)
+	conn.Signal(signals)
+	go m.dispatchSignals(signals)
+
+	return m, nil
+}
+
+func (m *dbusManager) dispatchSignals(signals <-chan *dbus.Signal) {
+	for signal := range signals {
+		switch signal.Name {
+		case systemdManagerIface + ".JobRemoved":
+			// JobRemoved(u id, o job, s unit, s result)
+			if len(signal.Body) < 4 {
+				continue
+			}
+			job, _ := signal.Body[1].(dbus.ObjectPath)
+			result, _ := signal.Body[3].(string)
+
+			m.jobsMu.Lock()
+			if done, ok := m.jobs[job]; ok {
+				done <- result
+				delete(m.jobs, job)
+			} else if m.calling > 0 {
+				// Possibly the job of a call whose reply is still on its way
+				m.early[job] = result
+			}
+			m.jobsMu.Unlock()
+		case "org.freedesktop.DBus.Properties.PropertiesChanged",
+			systemdManagerIface + ".UnitNew",
+			systemdManagerIface + ".UnitRemoved":
+			m.noteUnitSignal(signal)
+		}
+	}
+}
+
+// noteUnitSignal records the unit a signal is about for watchLoop and wakes
+// it without waiting for it.
+func (m *dbusManager) noteUnitSignal(signal *dbus.Signal) {
+	m.watchMu.Lock()
+	if !m.watching {
+		m.watchMu.Unlock()
+		return
+	}
+	switch signal.Name {
+	case systemdManagerIface + ".UnitRemoved":
+		// UnitRemoved(s id, o unit)
+		if len(signal.Body) >= 2 {
+			name, _ := signal.Body[0].(string)
+			path, _ := signal.Body[1].(dbus.ObjectPath)
+			delete(m.changedUnits, path)
+			m.removedUnits[name] = true
+		}
+	case systemdManagerIface + ".UnitNew":
+		// UnitNew(s id, o unit)
+		if len(signal.Body) >= 2 {
+			name, _ := signal.Body[0].(string)
+			path, _ := signal.Body[1].(dbus.ObjectPath)
+			delete(m.removedUnits, name)
+			m.changedUnits[path] = true
+		}
+	default:
+		m.changedUnits[signal.Path] = true
+	}
+	m.watchMu.Unlock()
+
+	select {
+	case m.unitsDirty <- struct{}{}:
+	default:
+	}
+}
+
+// jobTimeout bounds how long runJob waits for a job, well past systemd's
+// default start and stop timeouts of 90s.
+const jobTimeout = 5 * time.Minute
+
+// runJob calls a Manager method that enqueues a job and blocks until systemd
+// reports the job as removed. Any result other than "done" is an error.
+func (m *dbusManager) runJob(method, name string, args ...interface{}) error {
+	ctx, cancel := context.WithTimeout(context.Background(), m.jobTimeout)
+	defer cancel()
+
+	// JobRemoved can arrive before the reply carrying the job path, so
+	// results are kept aside while calls are in flight.
+	m.jobsMu.Lock()
+	m.calling++
+	m.jobsMu.Unlock()
+
+	var job dbus.ObjectPath
+	err := m.systemd.CallWithContext(ctx, systemdManagerIface+"."+method, 0, append([]interface{}{name}, args...)...).Store(&job)
+
+	done := make(chan string, 1)
+	m.jobsMu.Lock()
+	m.calling--
+	if err == nil {
+		if result, ok := m.early[job]; ok {
+			done <- result
+			delete(m.early, job)
+		} else {
+			m.jobs[job] = done
+		}
+	}
+	if m.calling == 0 {
+		clear(m.early)
+	}
+	m.jobsMu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	select {
+	case result := <-done:
+		if result != "done" {
+			return fmt.Errorf("job for %s finished with result %q", name, result)
+		}
+		return nil
+	case <-ctx.Done():
+		m.jobsMu.Lock()
+		delete(m.jobs, job)
+		m.jobsMu.Unlock()
+		return fmt.Errorf("job for %s did not finish within %v", name, m.jobTimeout)
+	}
+}
+
+func (m *dbusManager) listUnits() ([]dbusUnitStatus, error) {
+	var units []dbusUnitStatus
+	err := m.systemd.Call(systemdManagerIface+".ListUnits", 0).Store(&units)
+	return units, err
+}
+
+func (m *dbusManager) listUnitFiles() ([]dbusUnitFile, error) {
+	var files []dbusUnitFile
+	err := m.systemd.Call(systemdManagerIface+".ListUnitFiles", 0).Store(&files)
+	return files, err
+}
+
+func (m *dbusManager) ListUnits(unitType string) ([]service, error) {
+	units, err := m.listUnits()
+	if err != nil {
+		return nil, err
+	}
+
+	var services []service
+	for _, u := range units {
+		if unitType != "" && unitTypeOf(u.Name) != unitType {
+			continue
+		}
+		services = append(services, service{
+			name:        u.Name,
+			description: u.Description,
+			loaded:      u.LoadState,
+			active:      u.ActiveState,
+			sub:         u.SubState,
+		})
+	}
+
+	// Without unit file states every unit is shown with an unknown state
+	states := make(map[string]string)
+	if files, err := m.listUnitFiles(); err == nil {
+		for _, f := range files {
+			states[path.Base(f.Path)] = f.State
+		}
+	}
+	return mergeUnitFileStates(services, states, unitType), nil
+}
+
+// unitSignalDelay batches bursts of unit signals, e.g. the several
+// PropertiesChanged emitted while a unit restarts, into one update.
+const unitSignalDelay = 200 * time.Millisecond
+
+// WatchUnits implements unitWatcher using PropertiesChanged, UnitNew and
+// UnitRemoved signals.
+func (m *dbusManager) WatchUnits(done <-chan struct{}) (<-chan []unitChange, error) {
+	matches := [][]dbus.MatchOption{
+		{
+			dbus.WithMatchPathNamespace(systemdObjectPath + "/unit"),
+			dbus.WithMatchInterface("org.freedesktop.DBus.Properties"),
+			dbus.WithMatchMember("PropertiesChanged"),
+			dbus.WithMatchArg(0, systemdUnitIface),
+		},
+		{
+			dbus.WithMatchObjectPath(systemdObjectPath),
+			dbus.WithMatchInterface(systemdManagerIface),
+			dbus.WithMatchMember("UnitNew"),
+		},
+		{
+			dbus.WithMatchObjectPath(systemdObjectPath),
+			dbus.WithMatchInterface(systemdManagerIface),
+			dbus.WithMatchMember("UnitRemoved"),
+		},
+	}
+	for _, match := range matches {
+		if err := m.conn.AddMatchSignal(match...); err != nil {
+			return nil, err
+		}
+	}
+
+	m.watchMu.Lock()
+	m.watching = true
+	m.watchMu.Unlock()
+
+	out := make(chan []unitChange)
+	go func() {
+		m.watchLoop(out, done)
+
+		m.watchMu.Lock()
+		m.watching = false
+		clear(m.changedUnits)
+		clear(m.removedUnits)
+		m.watchMu.Unlock()
+		for _, match := range matches {
+			m.conn.RemoveMatchSignal(match...)
+		}
+		close(out)
+	}()
+	return out, nil
+}
+
+// watchLoop reads the state of the units noted by noteUnitSignal once their
+// signals settle and sends the changes on out, until done is closed.
+func (m *dbusManager) watchLoop(out chan<- []unitChange, done <-chan struct{}) {
+	flush := time.NewTimer(unitSignalDelay)
+	flush.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-m.unitsDirty:
+			flush.Reset(unitSignalDelay)
+		case <-flush.C:
+			m.watchMu.Lock()
+			changed, removed := m.changedUnits, m.removedUnits
+			m.changedUnits = make(map[dbus.ObjectPath]bool)
+			m.removedUnits = make(map[string]bool)
+			m.watchMu.Unlock()
+
+			var changes []unitChange
+			for path := range changed {
+				if c, ok := m.unitChange(path); ok {
+					changes = append(changes, c)
+				}
+			}
+			for name := range removed {
+				changes = append(changes, unitChange{service: service{name: name}, removed: true})
+			}
+			if len(changes) > 0 {
+				select {
+				case out <- changes:
+				case <-done:
+					return
+				}
+			}
+		}
+	}
+}
+
+// unitChange reads the current state of the unit at path.
+func (m *dbusManager) unitChange(path dbus.ObjectPath) (unitChange, bool) {
+	var values map[string]dbus.Variant
+	err := m.conn.Object(systemdBusName, path).Call("org.freedesktop.DBus.Properties.GetAll", 0, systemdUnitIface).Store(&values)
+	if err != nil {
+		return unitChange{}, false
+	}
+	prop := func(key string) string {
+		s, _ := values[key].Value().(string)
+		return s
+	}
+
+	return unitChange{service: service{
+		name:        prop("Id"),
+		description: prop("Description"),
+		loaded:      prop("LoadState"),
+		active:      prop("ActiveState"),
+		sub:         prop("SubState"),
+		enabled:     prop("UnitFileState"),
+	}}, true
+}
+
+// unitObject returns the bus object for a unit, loading it if necessary.
+func (m *dbusManager) unitObject(name string) (dbus.BusObject, error) {
+	var path dbus.ObjectPath
+	if err := m.systemd.Call(systemdManagerIface+".LoadUnit", 0, name).Store(&path); err != nil {
+		return nil, err
+	}
+	return m.conn.Object(systemdBusName, path), nil
+}
+
+func (m *dbusManager) ServiceState(name string) (string, error) {
+	unit, err := m.unitObject(name)
+	if err != nil {
+		return "", err
+	}
+	v, err := unit.GetProperty(systemdUnitIface + ".ActiveState")
+	if err != nil {
+		return "", err
+	}
+	state, _ := v.Value().(string)
+	return state, nil
+}
+
+func (m *dbusManager) Start(name string) error {
+	return m.runJob("StartUnit", name, "replace")
+}
+
+func (m *dbusManager) Stop(name string) error {
+	return m.runJob("StopUnit", name, "replace")
+}
+
+func (m *dbusManager) Restart(name string) error {
+	return m.runJob("RestartUnit", name, "replace")
+}
+
+func (m *dbusManager) Enable(name string) error {
+	var carriesInstallInfo bool
+	var changes []dbusUnitFileChange
+	err := m.systemd.Call(systemdManagerIface+".EnableUnitFiles", 0, []string{name}, false, false).Store(&carriesInstallInfo, &changes)
+	if err != nil {
+		return err
+	}
+	return m.reload()
+}
+
+func (m *dbusManager) Disable(name string) error {
+	var changes []dbusUnitFileChange
+	err := m.systemd.Call(systemdManagerIface+".DisableUnitFiles", 0, []string{name}, false).Store(&changes)
+	if err != nil {
+		return err
+	}
+	return m.reload()
+}
+
+func (m *dbusManager) Mask(name string) error {
+	var changes []dbusUnitFileChange
+	err := m.systemd.Call(systemdManagerIface+".MaskUnitFiles", 0, []string{name}, false, false).Store(&changes)
+	if err != nil {
+		return err
+	}
+	return m.reload()
+}
+
+func (m *dbusManager) Unmask(name string) error {
+	var changes []dbusUnitFileChange
+	err := m.systemd.Call(systemdManagerIface+".UnmaskUnitFiles", 0, []string{name}, false).Store(&changes)
+	if err != nil {
+		return err
+	}
+	return m.reload()
+}
+
+func (m *dbusManager) Reload(name string) error {
+	return m.runJob("ReloadUnit", name, "replace")
+}
+
+func (m *dbusManager) TryRestart(name string) error {
+	return m.runJob("TryRestartUnit", name, "replace")
+}
+
+func (m *dbusManager) ReloadOrRestart(name string) error {
+	return m.runJob("ReloadOrRestartUnit", name, "replace")
+}
+
+func (m *dbusManager) Isolate(name string) error {
+	return m.runJob("StartUnit", name, "isolate")
+}
+
+func (m *dbusManager) ResetFailed(name string) error {
+	if name == "" {
+		return m.systemd.Call(systemdManagerIface+".ResetFailed", 0).Err
+	}
+	return m.systemd.Call(systemdManagerIface+".ResetFailedUnit", 0, name).Err
+}
+
+func (m *dbusManager) Kill(name string, signal syscall.Signal) error {
+	return m.systemd.Call(systemdManagerIface+".KillUnit", 0, name, "all", int32(signal)).Err
+}
+
+func (m *dbusManager) DaemonReload() error {
+	return m.reload()
+}
+
+func (m *dbusManager) reload() error {
+	return m.systemd.Call(systemdManagerIface+".Reload", 0).Err
+}
+
+func (m *dbusManager) Properties(name string) (map[string]string, error) {
+	unit, err := m.unitObject(name)
+	if err != nil {
+		return nil, err
+	}
+
+	ifaces := []string{systemdUnitIface}
+	if typeIface := unitTypeInterface(name); typeIface != "" {
+		ifaces = append(ifaces, typeIface)
+	}
+
+	props := make(map[string]string)
+	for _, iface := range ifaces {
+		var values map[string]dbus.Variant
+		err := unit.Call("org.freedesktop.DBus.Properties.GetAll", 0, iface).Store(&values)
+		if err != nil {
+			return nil, err
+		}
+		for key, value := range values {
+			props[key] = formatDBusValue(value.Value())
+		}
+	}
+	return props, nil
+}
+
+func (m *dbusManager) UnitsProperties(names []string, props []string) (map[string]map[string]string, error) {
+	result := make(map[string]map[string]string, len(names))
+	for _, name := range names {
+		all, err := m.Properties(name)
+		if err != nil {
+			continue
+		}
+		unitProps := make(map[string]string, len(props))
+		for _, prop := range props {
+			unitProps[prop] = all[prop]
+		}
+		result[name] = unitProps
+	}
+	return result, nil
+}
+
+// unitTypeInterface returns the type-specific interface of a unit, e.g.
+// org.freedesktop.systemd1.Service for foo.service.
+func unitTypeInterface(name string) string {
+	dot := strings.LastIndex(name, ".")
+	if dot < 0 || dot == len(name)-1 {
+		return ""
+	}
+	suffix := name[dot+1:]
+	return "org.freedesktop.systemd1." + strings.ToUpper(suffix[:1]) + suffix[1:]
+}
+
+// formatDBusValue renders a property value the way `systemctl show` would.
+func formatDBusValue(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case bool:
+		if v {
+			return "yes"
+		}
+		return "no"
+	case dbus.ObjectPath:
+		return string(v)
+	case []string:
+		return strings.Join(v, " ")
+	case []interface{}:
+		parts := make([]string, 0, len(v))
+		for _, e := range v {
+			parts = append(parts, formatDBusValue(e))
+		}
+		return strings.Join(parts, " ")
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// busConfig is a private bus that anyone on the machine may use, enough to
+// own org.freedesktop.systemd1 without the system bus policy.
+const busConfig = `<!DOCTYPE busconfig PUBLIC "-//freedesktop//DTD D-Bus Bus Configuration 1.0//EN"
+ "http://www.freedesktop.org/standards/dbus/1.0/busconfig.dtd">
+<busconfig>
+  <type>session</type>
+  <listen>unix:path=%s</listen>
+  <auth>EXTERNAL</auth>
+  <policy context="default">
+    <allow send_destination="*" eavesdrop="true"/>
+    <allow eavesdrop="true"/>
+    <allow own="*"/>
+  </policy>
+</busconfig>
+`
+
+// startBus runs a dbus-daemon for the test and returns its address.
+func startBus(t *testing.T) string {
+	t.Helper()
+	daemon, err := exec.LookPath("dbus-daemon")
+	if err != nil {
+		t.Skip("dbus-daemon not installed")
+	}
+	dir := t.TempDir()
+	config := filepath.Join(dir, "bus.conf")
+	if err := os.WriteFile(config, []byte(fmt.Sprintf(busConfig, filepath.Join(dir, "bus"))), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := exec.Command(daemon, "--config-file="+config, "--nofork", "--print-address")
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	})
+	address, err := bufio.NewReader(stdout).ReadString('\n')
+	if err != nil {
+		t.Fatalf("reading the bus address: %v", err)
+	}
+	return strings.TrimSpace(address)
+}
+
+func connectBus(t *testing.T, address string) *dbus.Conn {
+	t.Helper()
+	conn, err := dbus.Connect(address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// stubSystemd serves the part of org.freedesktop.systemd1.Manager the tests
+// use. Jobs finish right away with the result set for their unit, emitting
+// JobRemoved before StartUnit replies like a fast job on a busy bus would.
+type stubSystemd struct {
+	conn *dbus.Conn
+
+	mu      sync.Mutex
+	results map[string]string // by unit, "" never finishes the job
+	lastJob uint32
+}
+
+func (s *stubSystemd) Subscribe() *dbus.Error {
+	return nil
+}
+
+func (s *stubSystemd) ListUnits() ([]dbusUnitStatus, *dbus.Error) {
+	return []dbusUnitStatus{
+		{Name: "nginx.service", Description: "A high performance web server", LoadState: "loaded", ActiveState: "active", SubState: "running", Path: "/org/freedesktop/systemd1/unit/nginx_2eservice", JobPath: "/"},
+		{Name: "getty@tty1.service", Description: "Getty on tty1", LoadState: "loaded", ActiveState: "active", SubState: "running", Path: "/org/freedesktop/systemd1/unit/getty_40tty1_2eservice", JobPath: "/"},
+		{Name: "backup.service", Description: "Nightly backup", LoadState: "loaded", ActiveState: "failed", SubState: "failed", Path: "/org/freedesktop/systemd1/unit/backup_2eservice", JobPath: "/"},
+		{Name: "logrotate.timer", Description: "Daily rotation of log files", LoadState: "loaded", ActiveState: "active", SubState: "waiting", Path: "/org/freedesktop/systemd1/unit/logrotate_2etimer", JobPath: "/"},
+	}, nil
+}
+
+func (s *stubSystemd) ListUnitFiles() ([]dbusUnitFile, *dbus.Error) {
+	return []dbusUnitFile{
+		{Path: "/lib/systemd/system/nginx.service", State: "enabled"},
+		{Path: "/lib/systemd/system/getty@.service", State: "enabled"},
+		{Path: "/lib/systemd/system/backup.service", State: "static"},
+		{Path: "/lib/systemd/system/cups.service", State: "disabled"},
+		{Path: "/lib/systemd/system/logrotate.timer", State: "enabled"},
+	}, nil
+}
+
+func (s *stubSystemd) StartUnit(name, mode string) (dbus.ObjectPath, *dbus.Error) {
+	s.mu.Lock()
+	s.lastJob++
+	id := s.lastJob
+	result := s.results[name]
+	s.mu.Unlock()
+
+	job := dbus.ObjectPath(fmt.Sprintf("/org/freedesktop/systemd1/job/%d", id))
+	if result != "" {
+		s.conn.Emit(systemdObjectPath, systemdManagerIface+".JobRemoved", id, job, name, result)
+	}
+	return job, nil
+}
+
+// newStubbedDBusManager runs the D-Bus backend against stubSystemd on a
+// private bus. The stub's connection is returned to emit signals on.
+func newStubbedDBusManager(t *testing.T, results map[string]string) (*dbusManager, *dbus.Conn) {
+	t.Helper()
+	address := startBus(t)
+
+	server := connectBus(t, address)
+	stub := &stubSystemd{conn: server, results: results}
+	if err := server.Export(stub, systemdObjectPath, systemdManagerIface); err != nil {
+		t.Fatal(err)
+	}
+	reply, err := server.RequestName(systemdBusName, dbus.NameFlagDoNotQueue)
+	if err != nil || reply != dbus.RequestNameReplyPrimaryOwner {
+		t.Fatalf("owning %s: %v, %v", systemdBusName, reply, err)
+	}
+
+	m, err := newDBusManagerWithConn(connectBus(t, address))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m, server
+}
+
+func TestDBusListUnits(t *testing.T) {
+	m, _ := newStubbedDBusManager(t, nil)
+
+	units, err := m.ListUnits("service")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]service)
+	for _, s := range units {
+		got[s.name] = s
+	}
+	tests := []struct {
+		name    string
+		active  string
+		enabled string
+	}{
+		{"nginx.service", "active", "enabled"},
+		{"getty@tty1.service", "active", "enabled"}, // from getty@.service
+		{"backup.service", "failed", "static"},
+		{"cups.service", "inactive", "disabled"}, // unit file only
+	}
+	for _, tt := range tests {
+		s, ok := got[tt.name]
+		if !ok {
+			t.Errorf("%s: missing from %v", tt.name, units)
+			continue
+		}
+		if s.active != tt.active || s.enabled != tt.enabled {
+			t.Errorf("%s: active %q enabled %q, want %q %q", tt.name, s.active, s.enabled, tt.active, tt.enabled)
+		}
+	}
+	if _, ok := got["logrotate.timer"]; ok || len(units) != len(tests) {
+		t.Errorf("listed %v, want only the %d services", units, len(tests))
+	}
+}
+
+func TestDBusStartJobResult(t *testing.T) {
+	m, _ := newStubbedDBusManager(t, map[string]string{
+		"nginx.service":  "done",
+		"backup.service": "failed",
+	})
+
+	if err := m.Start("nginx.service"); err != nil {
+		t.Errorf("start of a job removed with done: %v", err)
+	}
+	err := m.Start("backup.service")
+	if err == nil || !strings.Contains(err.Error(), `"failed"`) {
+		t.Errorf("start of a job removed with failed: %v, want the result in an error", err)
+	}
+	m.jobsMu.Lock()
+	defer m.jobsMu.Unlock()
+	if len(m.jobs) != 0 || len(m.early) != 0 {
+		t.Errorf("jobs %v, early results %v left behind", m.jobs, m.early)
+	}
+}
+
+func TestDBusJobTimeout(t *testing.T) {
+	m, _ := newStubbedDBusManager(t, map[string]string{"nginx.service": "done"})
+	m.jobTimeout = 200 * time.Millisecond
+
+	// The stub never removes the job of a unit without a result
+	err := m.Start("stuck.service")
+	if err == nil || !strings.Contains(err.Error(), "did not finish") {
+		t.Fatalf("start of a job never removed: %v, want a timeout", err)
+	}
+	// A missed signal must not hold up later jobs
+	if err := m.Start("nginx.service"); err != nil {
+		t.Errorf("start after a timed out job: %v", err)
+	}
+}
+
+// stubUnit answers Properties.GetAll for one unit, so watchLoop has states to
+// read.
+type stubUnit struct {
+	name string
+}
+
+func (u stubUnit) GetAll(iface string) (map[string]dbus.Variant, *dbus.Error) {
+	return map[string]dbus.Variant{
+		"Id":          dbus.MakeVariant(u.name),
+		"LoadState":   dbus.MakeVariant("loaded"),
+		"ActiveState": dbus.MakeVariant("active"),
+		"SubState":    dbus.MakeVariant("running"),
+	}, nil
+}
+
+func TestDBusJobResultDuringUnitSignals(t *testing.T) {
+	m, server := newStubbedDBusManager(t, map[string]string{"nginx.service": "done"})
+	m.jobTimeout = 2 * time.Second
+	unitPath := dbus.ObjectPath("/org/freedesktop/systemd1/unit/nginx_2eservice")
+	if err := server.Export(stubUnit{name: "nginx.service"}, unitPath, "org.freedesktop.DBus.Properties"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Nobody reads the changes, like a TUI busy elsewhere
+	done := make(chan struct{})
+	t.Cleanup(func() { close(done) })
+	if _, err := m.WatchUnits(done); err != nil {
+		t.Fatal(err)
+	}
+	emit := func(n int) {
+		for i := 0; i < n; i++ {
+			server.Emit(unitPath, "org.freedesktop.DBus.Properties.PropertiesChanged", systemdUnitIface, map[string]dbus.Variant{}, []string{})
+		}
+	}
+	// The first change leaves watchLoop stuck sending it, the rest pile up
+	emit(1)
+	time.Sleep(2 * unitSignalDelay)
+	emit(500)
+
+	start := time.Now()
+	if err := m.Start("nginx.service"); err != nil {
+		t.Fatalf("start while unit signals pile up: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("start took %v behind the unit signals", elapsed)
+	}
+}
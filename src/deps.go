@@ -0,0 +1,310 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// dependencyModes are the relations the tree can follow, cycled with `m`.
+// The first two match `systemctl list-dependencies` and its --reverse.
+var dependencyModes = []struct {
+	label      string
+	properties []string
+}{
+	{"Requires/Wants", []string{"Requires", "Requisite", "Wants", "ConsistsOf", "BindsTo", "Upholds"}},
+	{"Required/Wanted by", []string{"RequiredBy", "RequisiteOf", "WantedBy", "PartOf", "BoundBy", "UpheldBy"}},
+	{"After", []string{"After"}},
+	{"Before", []string{"Before"}},
+}
+
+// unitStateProperties are enough to build a service for any unit.
+var unitStateProperties = []string{"Description", "LoadState", "ActiveState", "SubState", "UnitFileState"}
+
+// depNode is one unit of the dependency tree. Children are fetched the
+// first time the node is expanded.
+type depNode struct {
+	unit     service
+	relation string // property linking the node to its parent
+	parent   *depNode
+	children []*depNode
+	expanded bool
+	loaded   bool
+	loading  bool
+}
+
+// cycle reports whether the node's unit already appears above it, in which
+// case it is not expanded again.
+func (n *depNode) cycle() bool {
+	for p := n.parent; p != nil; p = p.parent {
+		if p.unit.name == n.unit.name {
+			return true
+		}
+	}
+	return false
+}
+
+type depsLoadedMsg struct {
+	node     *depNode
+	children []*depNode
+	err      error
+}
+
+// depsView is the state of the dependency tree opened with `D`.
+type depsView struct {
+	root   *depNode
+	mode   int // index into dependencyModes
+	cursor int // index into visible()
+	offset int // first visible row on screen
+	err    error
+}
+
+func serviceFromProperties(name string, p map[string]string) service {
+	return service{
+		name:        name,
+		description: p["Description"],
+		loaded:      p["LoadState"],
+		active:      p["ActiveState"],
+		sub:         p["SubState"],
+		enabled:     p["UnitFileState"],
+	}
+}
+
+func loadDependencies(manager ServiceManager, node *depNode, mode int) tea.Cmd {
+	props := dependencyModes[mode].properties
+	name := node.unit.name
+	return func() tea.Msg {
+		deps, err := manager.UnitsProperties([]string{name}, props)
+		if err != nil {
+			return depsLoadedMsg{node: node, err: err}
+		}
+
+		relations := make(map[string]string)
+		var names []string
+		for _, prop := range props {
+			for _, dep := range strings.Fields(deps[name][prop]) {
+				if _, ok := relations[dep]; !ok {
+					relations[dep] = prop
+					names = append(names, dep)
+				}
+			}
+		}
+		sort.Strings(names)
+
+		// Units that cannot be queried are still shown, only without state
+		states, _ := manager.UnitsProperties(names, unitStateProperties)
+		children := make([]*depNode, 0, len(names))
+		for _, dep := range names {
+			children = append(children, &depNode{
+				unit:     serviceFromProperties(dep, states[dep]),
+				relation: relations[dep],
+				parent:   node,
+			})
+		}
+		return depsLoadedMsg{node: node, children: children}
+	}
+}
+
+// visible returns the nodes currently shown, depth first.
+func (v depsView) visible() []*depNode {
+	var nodes []*depNode
+	var walk func(n *depNode)
+	walk = func(n *depNode) {
+		nodes = append(nodes, n)
+		if n.expanded {
+			for _, c := range n.children {
+				walk(c)
+			}
+		}
+	}
+	if v.root != nil {
+		walk(v.root)
+	}
+	return nodes
+}
+
+func (v depsView) selected() *depNode {
+	nodes := v.visible()
+	if v.cursor < 0 || v.cursor >= len(nodes) {
+		return nil
+	}
+	return nodes[v.cursor]
+}
+
+// moveCursor moves the selection by delta rows and keeps it on screen.
+func (v *depsView) moveCursor(delta, height int) {
+	v.cursor += delta
+	if last := len(v.visible()) - 1; v.cursor > last {
+		v.cursor = last
+	}
+	if v.cursor < 0 {
+		v.cursor = 0
+	}
+	if v.cursor < v.offset {
+		v.offset = v.cursor
+	}
+	if height > 0 && v.cursor >= v.offset+height {
+		v.offset = v.cursor - height + 1
+	}
+}
+
+// expandDependency shows the children of n, loading them the first time.
+func (m model) expandDependency(n *depNode) (model, tea.Cmd) {
+	if n.cycle() {
+		return m, nil
+	}
+	n.expanded = true
+	if n.loaded || n.loading {
+		return m, nil
+	}
+	n.loading = true
+	return m, loadDependencies(m.manager, n, m.deps.mode)
+}
+
+// openDependencies shows the dependency tree rooted at s.
+func (m model) openDependencies(s service) (model, tea.Cmd) {
+	m.deps = depsView{root: &depNode{unit: s}, mode: m.deps.mode}
+	m.showDeps = true
+	return m.expandDependency(m.deps.root)
+}
+
+func (m model) depsHeight() int {
+	return m.height - 5
+}
+
+func (m model) updateDependencies(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "q", "esc", "D":
+		m.showDeps = false
+		return m, nil
+	case "ctrl+c":
+		return m, tea.Quit
+	case "j", "down":
+		m.deps.moveCursor(1, m.depsHeight())
+		return m, nil
+	case "k", "up":
+		m.deps.moveCursor(-1, m.depsHeight())
+		return m, nil
+	case "g":
+		m.deps.moveCursor(-len(m.deps.visible()), m.depsHeight())
+		return m, nil
+	case "G":
+		m.deps.moveCursor(len(m.deps.visible()), m.depsHeight())
+		return m, nil
+	case "m":
+		m.deps.mode = (m.deps.mode + 1) % len(dependencyModes)
+		return m.openDependencies(m.deps.root.unit)
+	case "r":
+		return m.openDependencies(m.deps.root.unit)
+	}
+
+	n := m.deps.selected()
+	if n == nil {
+		return m, nil
+	}
+	switch msg.String() {
+	case "enter", " ":
+		if n.expanded {
+			n.expanded = false
+			return m, nil
+		}
+		return m.expandDependency(n)
+	case "right":
+		return m.expandDependency(n)
+	case "left":
+		if n.expanded {
+			n.expanded = false
+			return m, nil
+		}
+		// Jump to the parent, like most tree views
+		for i, v := range m.deps.visible() {
+			if v == n.parent {
+				m.deps.moveCursor(i-m.deps.cursor, m.depsHeight())
+				break
+			}
+		}
+		return m, nil
+	case "o":
+		return m.openDependencies(n.unit)
+	case "i":
+		return m.openInspector(n.unit.name)
+	case "l":
+		return m.openLogs(n.unit.name)
+	case "p":
+		return m.openProcesses(n.unit.name)
+	case "a":
+		m.showDeps = false
+		return m.openMenu(n.unit)
+	}
+	return m, nil
+}
+
+// dependencyLines draws the visible part of the tree with box characters.
+func (m model) dependencyLines() []string {
+	selectedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#FAFAFA")).Background(lipgloss.Color("#7D56F4"))
+	lineStyle := lipgloss.NewStyle().MaxWidth(m.width)
+
+	var lines []string
+	var walk func(n *depNode, prefix string, last bool, root bool)
+	walk = func(n *depNode, prefix string, last bool, root bool) {
+		branch, childPrefix := "", ""
+		if !root {
+			branch, childPrefix = "├─ ", prefix+"│  "
+			if last {
+				branch, childPrefix = "└─ ", prefix+"   "
+			}
+		}
+
+		marker := "▸ "
+		switch {
+		case n.cycle():
+			marker = "↻ "
+		case n.loading:
+			marker = "… "
+		case n.loaded && len(n.children) == 0:
+			marker = "  "
+		case n.expanded:
+			marker = "▾ "
+		}
+
+		label := marker + n.unit.Title()
+		if n.relation != "" {
+			label += " " + helpStyle.Render(n.relation)
+		}
+		if len(lines) == m.deps.cursor {
+			label = selectedStyle.Render(label)
+		}
+		lines = append(lines, lineStyle.Render(prefix+branch+label))
+
+		if n.expanded {
+			for i, c := range n.children {
+				walk(c, childPrefix, i == len(n.children)-1, false)
+			}
+		}
+	}
+	if m.deps.root != nil {
+		walk(m.deps.root, "", true, true)
+	}
+	return lines
+}
+
+func (m model) dependenciesView() string {
+	mode := dependencyModes[m.deps.mode]
+	header := titleStyle.Render(fmt.Sprintf("🌳 Dependencies: %s", m.deps.root.unit.name)) + " " +
+		helpStyle.Render(fmt.Sprintf("%s (%s)", mode.label, strings.Join(mode.properties, ", ")))
+
+	lines := m.dependencyLines()
+	if end := m.deps.offset + m.depsHeight(); m.depsHeight() > 0 && end < len(lines) {
+		lines = lines[:end]
+	}
+	lines = lines[m.deps.offset:]
+	if m.deps.err != nil {
+		lines = append(lines, "", fmt.Sprintf("❌ Error loading dependencies: %v", m.deps.err))
+	}
+
+	help := helpStyle.Render("j/k: Navigate | Enter/Space: Expand/Collapse | ←/→: Collapse/Expand | m: Mode | o: Root here | i: Inspect | l: Logs | p: Processes | a: Actions | r: Reload | q/Esc: Close")
+	return lipgloss.JoinVertical(lipgloss.Left, header, "", strings.Join(lines, "\n"), "", help)
+}
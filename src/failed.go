@@ -0,0 +1,176 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// failedProperties explain why a unit failed.
+var failedProperties = []string{"Result", "ExecMainCode", "ExecMainStatus"}
+
+// exitStatusNames are the names systemctl status gives to well-known exit
+// codes, see systemd.exec(5).
+var exitStatusNames = map[int]string{
+	1: "FAILURE", 2: "INVALIDARGUMENT", 3: "NOTIMPLEMENTED", 4: "NOPERMISSION",
+	5: "NOTINSTALLED", 6: "NOTCONFIGURED", 7: "NOTRUNNING",
+	200: "CHDIR", 201: "NICE", 202: "FDS", 203: "EXEC", 204: "MEMORY",
+	205: "LIMITS", 206: "OOM_ADJUST", 207: "SIGNAL_MASK", 208: "STDIN",
+	209: "STDOUT", 210: "CHROOT", 211: "IOPRIO", 212: "TIMERSLACK",
+	213: "SECUREBITS", 214: "SETSCHEDULER", 215: "CPUAFFINITY", 216: "GROUP",
+	217: "USER", 218: "CAPABILITIES", 219: "CGROUP", 220: "SETSID",
+	221: "CONFIRM", 222: "STDERR", 224: "PAM", 225: "NETWORK",
+	226: "NAMESPACE", 227: "NO_NEW_PRIVILEGES", 228: "SECCOMP",
+	231: "PERSONALITY", 232: "APPARMOR", 233: "ADDRESS_FAMILIES",
+	236: "RUNTIME_DIRECTORY", 238: "STATE_DIRECTORY", 243: "CREDENTIALS",
+}
+
+type failedLoadedMsg struct {
+	failed []list.Item
+	err    error
+}
+
+// bulkResult is the outcome of a bulk action on one unit.
+type bulkResult struct {
+	unit  string
+	state string // active state after the action
+	err   error
+}
+
+type bulkResultsMsg struct {
+	title   string
+	results []bulkResult
+}
+
+// loadFailedUnits lists the failed units of every type with the reason they
+// failed in the details column.
+func loadFailedUnits(manager ServiceManager) tea.Cmd {
+	return func() tea.Msg {
+		units, err := manager.ListUnits("")
+		if err != nil {
+			return failedLoadedMsg{err: err}
+		}
+
+		var failed []service
+		var names []string
+		for _, s := range units {
+			if s.active == "failed" {
+				failed = append(failed, s)
+				names = append(names, s.name)
+			}
+		}
+		if len(failed) == 0 {
+			return failedLoadedMsg{}
+		}
+
+		// Without the properties the units are still listed, only unexplained
+		props, _ := manager.UnitsProperties(names, failedProperties)
+		for i, s := range failed {
+			failed[i].details = failureDetails(props[s.name])
+		}
+		return failedLoadedMsg{failed: toListItems(failed)}
+	}
+}
+
+// failureDetails renders the result of a failed unit and how its main
+// process ended, e.g. "💥 exit-code · status=1/FAILURE".
+func failureDetails(p map[string]string) string {
+	result := p["Result"]
+	if result == "" {
+		result = "failed"
+	}
+	if status := formatExitStatus(p["ExecMainCode"], p["ExecMainStatus"]); status != "" {
+		return "💥 " + result + " · " + status
+	}
+	return "💥 " + result
+}
+
+// formatExitStatus formats the main process exit the way systemctl status
+// does. The code is a CLD_* value from waitid(2); units that never ran a main
+// process report none.
+func formatExitStatus(code, status string) string {
+	c, err := strconv.Atoi(code)
+	if err != nil {
+		return ""
+	}
+	n, err := strconv.Atoi(status)
+	if err != nil {
+		return ""
+	}
+
+	switch c {
+	case 1: // CLD_EXITED
+		if name, ok := exitStatusNames[n]; ok {
+			return fmt.Sprintf("status=%d/%s", n, name)
+		}
+		return fmt.Sprintf("status=%d", n)
+	case 2, 3: // CLD_KILLED, CLD_DUMPED
+		signal := syscall.Signal(n).String()
+		if c == 3 {
+			return fmt.Sprintf("signal=%d (%s), core dumped", n, signal)
+		}
+		return fmt.Sprintf("signal=%d (%s)", n, signal)
+	}
+	return ""
+}
+
+// failedUnitActions is the action menu of the failed pane.
+var failedUnitActions = []serviceAction{{"Restart", "restart"}, {"Reset failed state", "reset-failed"}, {"Stop", "stop"}}
+
+// restartUnits restarts each unit in turn and reports how every one of them
+// ended up, so a unit that fails again right away is not reported as fixed.
+func restartUnits(manager ServiceManager, names []string) tea.Cmd {
+	return func() tea.Msg {
+		results := make([]bulkResult, 0, len(names))
+		for _, name := range names {
+			r := bulkResult{unit: name}
+			if r.err = manager.Restart(name); r.err == nil {
+				r.state, _ = manager.ServiceState(name)
+			}
+			results = append(results, r)
+		}
+		return bulkResultsMsg{title: "🔁 Restart all failed units", results: results}
+	}
+}
+
+func resetAllFailed(manager ServiceManager) tea.Cmd {
+	return func() tea.Msg {
+		if err := manager.ResetFailed(""); err != nil {
+			return messageMsg{text: fmt.Sprintf("❌ Failed to reset failed units: %v", err)}
+		}
+		return messageMsg{text: "✅ Successfully reset the failed state of all units"}
+	}
+}
+
+// failedUnitNames returns the units currently listed in the failed pane.
+func (m model) failedUnitNames() []string {
+	return serviceNames(m.failedServices.Items())
+}
+
+func (m model) bulkReportView() string {
+	var ok, failed int
+	var lines []string
+	for _, r := range m.bulkReport.results {
+		switch {
+		case r.err != nil:
+			failed++
+			lines = append(lines, fmt.Sprintf("❌ %s: %v", r.unit, r.err))
+		case r.state != "active" && r.state != "activating" && r.state != "reloading":
+			failed++
+			lines = append(lines, fmt.Sprintf("❌ %s: %s after restart", r.unit, r.state))
+		default:
+			ok++
+			lines = append(lines, fmt.Sprintf("✅ %s: %s", r.unit, r.state))
+		}
+	}
+
+	content := m.bulkReport.title + "\n\n"
+	content += fmt.Sprintf("%d succeeded, %d failed\n\n", ok, failed)
+	content += strings.Join(lines, "\n")
+	content += "\n\nEnter/Esc/q: Close"
+	return modalStyle.Render(content)
+}
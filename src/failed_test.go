@@ -0,0 +1,48 @@
+package main
+
+import "testing"
+
+func TestFormatExitStatus(t *testing.T) {
+	tests := []struct {
+		code, status string
+		want         string
+	}{
+		{"1", "0", "status=0"},
+		{"1", "1", "status=1/FAILURE"},
+		{"1", "203", "status=203/EXEC"},
+		{"1", "42", "status=42"},
+		{"2", "9", "signal=9 (killed)"},
+		{"2", "15", "signal=15 (terminated)"},
+		{"3", "11", "signal=11 (segmentation fault), core dumped"},
+		// No main process ran
+		{"0", "0", ""},
+		{"", "", ""},
+		{"1", "", ""},
+		{"x", "1", ""},
+	}
+	for _, tt := range tests {
+		if got := formatExitStatus(tt.code, tt.status); got != tt.want {
+			t.Errorf("formatExitStatus(%q, %q) = %q, want %q", tt.code, tt.status, got, tt.want)
+		}
+	}
+}
+
+func TestFailureDetails(t *testing.T) {
+	tests := []struct {
+		name  string
+		props map[string]string
+		want  string
+	}{
+		{"exit code", map[string]string{"Result": "exit-code", "ExecMainCode": "1", "ExecMainStatus": "1"}, "💥 exit-code · status=1/FAILURE"},
+		{"signal", map[string]string{"Result": "signal", "ExecMainCode": "2", "ExecMainStatus": "9"}, "💥 signal · signal=9 (killed)"},
+		{"core dump", map[string]string{"Result": "core-dump", "ExecMainCode": "3", "ExecMainStatus": "6"}, "💥 core-dump · signal=6 (aborted), core dumped"},
+		{"no main process", map[string]string{"Result": "timeout", "ExecMainCode": "0", "ExecMainStatus": "0"}, "💥 timeout"},
+		{"empty result", map[string]string{"Result": "", "ExecMainCode": "1", "ExecMainStatus": "2"}, "💥 failed · status=2/INVALIDARGUMENT"},
+		{"properties unavailable", nil, "💥 failed"},
+	}
+	for _, tt := range tests {
+		if got := failureDetails(tt.props); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type favoritesLoadedMsg struct {
+	names map[string]bool
+	units []list.Item
+	err   error
+}
+
+type favoriteToggledMsg struct {
+	unit     string
+	favorite bool
+	err      error
+}
+
+// loadFavorites lists the starred units of every type. Starred units systemd
+// has not loaded are still listed, so they can be found and unstarred.
+func loadFavorites(manager ServiceManager, db *sql.DB) tea.Cmd {
+	return func() tea.Msg {
+		names, err := loadFavoriteNames(db)
+		if err != nil {
+			return favoritesLoadedMsg{err: err}
+		}
+		if len(names) == 0 {
+			return favoritesLoadedMsg{names: names}
+		}
+		units, err := manager.ListUnits("")
+		if err != nil {
+			return favoritesLoadedMsg{err: err}
+		}
+
+		var favorites []service
+		found := make(map[string]bool)
+		for _, s := range units {
+			if names[s.name] {
+				favorites = append(favorites, s)
+				found[s.name] = true
+			}
+		}
+		for name := range names {
+			if !found[name] {
+				favorites = append(favorites, service{name: name, description: "Not loaded", loaded: "not-found", active: "inactive"})
+			}
+		}
+		sort.Slice(favorites, func(i, j int) bool { return favorites[i].name < favorites[j].name })
+		return favoritesLoadedMsg{names: names, units: toListItems(favorites)}
+	}
+}
+
+func toggleFavorite(db *sql.DB, unit string, favorite bool) tea.Cmd {
+	return func() tea.Msg {
+		return favoriteToggledMsg{unit: unit, favorite: favorite, err: updateFavorite(db, unit, favorite)}
+	}
+}
+
+// visiblePanes returns the panes of the main view, left to right: the
+// favorites pane once something is starred, the all and running panes, then
+// the failed pane when toggled with `F`.
+func (m model) visiblePanes() []int {
+	var panes []int
+	if len(m.favorites) > 0 {
+		panes = append(panes, paneFavorites)
+	}
+	panes = append(panes, paneAll, paneRunning)
+	if m.showFailed {
+		panes = append(panes, paneFailed)
+	}
+	return panes
+}
+
+// focusPane moves the focus step panes to the left or right, stopping at the
+// edges.
+func (m *model) focusPane(step int) {
+	panes := m.visiblePanes()
+	for i, pane := range panes {
+		if pane == m.focused && i+step >= 0 && i+step < len(panes) {
+			m.focused = panes[i+step]
+			return
+		}
+	}
+}
+
+// applyFavorites stars the favorite units in every list.
+func (m *model) applyFavorites() {
+	for _, l := range []*list.Model{&m.allServices, &m.runningServices, &m.failedServices, &m.favoriteServices} {
+		for i, item := range l.Items() {
+			if s, ok := item.(service); ok && s.favorite != m.favorites[s.name] {
+				s.favorite = m.favorites[s.name]
+				l.SetItem(i, s)
+			}
+		}
+	}
+}
+
+func (m model) starFocused() (model, tea.Cmd) {
+	s, ok := m.focusedService()
+	if !ok {
+		return m, nil
+	}
+	return m, toggleFavorite(m.db, s.name, !m.favorites[s.name])
+}
+
+func (m *model) setFavorites(msg favoritesLoadedMsg) {
+	if msg.err != nil {
+		m.message = fmt.Sprintf("❌ Error loading favorites: %v", msg.err)
+		return
+	}
+	first := m.favorites == nil
+	m.favorites = msg.names
+	m.favoriteServices.SetItems(msg.units)
+	switch {
+	case first && len(m.favorites) > 0:
+		// The units that matter most come first on startup
+		m.focused = paneFavorites
+	case len(m.favorites) == 0 && m.focused == paneFavorites:
+		m.focused = paneAll
+	}
+	m.resizeLists()
+	m.applyTags()
+	m.applyFavorites()
+	m.applyUsage()
+}
@@ -0,0 +1,285 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// pinnedProperties are shown first, in this order, whatever the search.
+var pinnedProperties = []string{
+	"Id", "Description", "LoadState", "ActiveState", "SubState", "UnitFileState", "Result",
+	"MainPID", "ExecStart", "Restart", "NRestarts", "MemoryCurrent", "CPUUsageNSec", "TasksCurrent",
+	"ActiveEnterTimestamp", "FragmentPath", "DropInPaths",
+}
+
+// propertySections groups the remaining properties. The first section whose
+// matcher accepts a key wins; anything left over goes to "Other".
+var propertySections = []struct {
+	title string
+	match func(key string) bool
+}{
+	{"⚙️  Execution", func(k string) bool {
+		return strings.HasPrefix(k, "Exec") || strings.HasPrefix(k, "Restart") ||
+			strings.HasSuffix(k, "PID") || strings.HasPrefix(k, "Kill") ||
+			k == "Type" || k == "User" || k == "Group" || k == "Environment" || k == "EnvironmentFiles"
+	}},
+	{"📊 Resources", func(k string) bool {
+		for _, prefix := range []string{"Memory", "CPU", "Tasks", "IO", "IP", "Limit", "OOM", "Startup"} {
+			if strings.HasPrefix(k, prefix) {
+				return true
+			}
+		}
+		return k == "Nice"
+	}},
+	{"🕒 Timestamps", func(k string) bool {
+		return strings.Contains(k, "Timestamp")
+	}},
+	{"🔗 Dependencies", func(k string) bool {
+		switch k {
+		case "Requires", "Requisite", "Wants", "BindsTo", "PartOf", "Upholds", "Conflicts",
+			"Before", "After", "OnFailure", "OnSuccess", "Triggers", "TriggeredBy",
+			"RequiredBy", "RequisiteOf", "WantedBy", "BoundBy", "UpheldBy", "ConsistsOf",
+			"ConflictedBy", "PropagatesReloadTo", "ReloadPropagatedFrom", "JoinsNamespaceOf":
+			return true
+		}
+		return false
+	}},
+	{"📁 Paths", func(k string) bool {
+		return strings.HasSuffix(k, "Path") || strings.HasSuffix(k, "Paths") ||
+			strings.HasSuffix(k, "Directory") || strings.HasSuffix(k, "Directories")
+	}},
+}
+
+// unsetUint64 is how systemd reports an unset or infinite numeric property.
+const unsetUint64 = "18446744073709551615"
+
+type propertiesLoadedMsg struct {
+	unit  string
+	props map[string]string
+	err   error
+}
+
+// inspectorView is the state of the property inspector opened with `i`.
+type inspectorView struct {
+	unit      string
+	props     map[string]string
+	err       error
+	loading   bool
+	viewport  viewport.Model
+	searching bool
+	search    textinput.Model
+}
+
+func loadProperties(manager ServiceManager, unit string) tea.Cmd {
+	return func() tea.Msg {
+		props, err := manager.Properties(unit)
+		return propertiesLoadedMsg{unit: unit, props: props, err: err}
+	}
+}
+
+// formatPropertyValue adds a human readable form to raw byte counts,
+// nanosecond counters and microsecond timestamps.
+func formatPropertyValue(key, value string) string {
+	if value == unsetUint64 {
+		return "[not set]"
+	}
+	n, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return value
+	}
+	switch {
+	case strings.HasSuffix(key, "NSec"):
+		return fmt.Sprintf("%s (%s)", value, time.Duration(n).Round(time.Millisecond))
+	case strings.HasSuffix(key, "USec"):
+		return fmt.Sprintf("%s (%s)", value, (time.Duration(n) * time.Microsecond).Round(time.Millisecond))
+	case strings.HasSuffix(key, "Timestamp") && n > 0:
+		return time.UnixMicro(int64(n)).Format("Mon 2006-01-02 15:04:05 MST")
+	case strings.HasPrefix(key, "Memory") || strings.HasSuffix(key, "Bytes"):
+		return fmt.Sprintf("%s (%s)", value, formatBytes(n))
+	}
+	return value
+}
+
+// formatBytes renders a byte count with a binary unit suffix, like systemctl.
+func formatBytes(n uint64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+	div, exp := uint64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%c", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// render lays out the pinned properties and every section matching the
+// current search.
+func (v *inspectorView) render() {
+	if v.loading {
+		v.viewport.SetContent("Loading properties...")
+		return
+	}
+	if v.err != nil {
+		v.viewport.SetContent(fmt.Sprintf("❌ Error loading properties: %v", v.err))
+		return
+	}
+
+	query := strings.ToLower(v.search.Value())
+	matches := func(key string) bool {
+		if query == "" {
+			return true
+		}
+		return strings.Contains(strings.ToLower(key), query) ||
+			strings.Contains(strings.ToLower(v.props[key]), query)
+	}
+
+	keyStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#7D56F4")).Bold(true)
+	lineStyle := lipgloss.NewStyle().MaxWidth(v.viewport.Width)
+	var lines []string
+	writeSection := func(title string, keys []string) {
+		var rows []string
+		for _, key := range keys {
+			if !matches(key) {
+				continue
+			}
+			value := formatPropertyValue(key, v.props[key])
+			rows = append(rows, lineStyle.Render(fmt.Sprintf("  %s=%s", keyStyle.Render(key), value)))
+		}
+		if len(rows) == 0 {
+			return
+		}
+		if len(lines) > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, aboutStyle.Render(title))
+		lines = append(lines, rows...)
+	}
+
+	pinned := make(map[string]bool)
+	var pinnedKeys []string
+	for _, key := range pinnedProperties {
+		if _, ok := v.props[key]; ok {
+			pinned[key] = true
+			pinnedKeys = append(pinnedKeys, key)
+		}
+	}
+	writeSection("📌 Pinned", pinnedKeys)
+
+	var rest []string
+	for key := range v.props {
+		if !pinned[key] {
+			rest = append(rest, key)
+		}
+	}
+	sort.Strings(rest)
+
+	grouped := make([][]string, len(propertySections)+1)
+	for _, key := range rest {
+		section := len(propertySections)
+		for i, s := range propertySections {
+			if s.match(key) {
+				section = i
+				break
+			}
+		}
+		grouped[section] = append(grouped[section], key)
+	}
+	for i, s := range propertySections {
+		writeSection(s.title, grouped[i])
+	}
+	writeSection("📦 Other", grouped[len(propertySections)])
+
+	if len(lines) == 0 {
+		lines = append(lines, helpStyle.Render(fmt.Sprintf("No properties matching '%s'", v.search.Value())))
+	}
+	v.viewport.SetContent(strings.Join(lines, "\n"))
+}
+
+// openInspector shows the property inspector for unit.
+func (m model) openInspector(unit string) (model, tea.Cmd) {
+	search := textinput.New()
+	search.Placeholder = "Filter properties..."
+	search.CharLimit = 50
+	search.Width = 40
+
+	m.inspector = inspectorView{
+		unit:     unit,
+		loading:  true,
+		viewport: viewport.New(m.width, m.height-5),
+		search:   search,
+	}
+	m.inspector.render()
+	m.showInspector = true
+	return m, loadProperties(m.manager, unit)
+}
+
+func (m model) updateInspector(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.inspector.searching {
+		switch msg.String() {
+		case "enter":
+			m.inspector.searching = false
+			m.inspector.search.Blur()
+			return m, nil
+		case "esc":
+			m.inspector.searching = false
+			m.inspector.search.Blur()
+			m.inspector.search.SetValue("")
+			m.inspector.render()
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.inspector.search, cmd = m.inspector.search.Update(msg)
+		m.inspector.render()
+		m.inspector.viewport.GotoTop()
+		return m, cmd
+	}
+
+	switch msg.String() {
+	case "q", "esc", "i":
+		m.showInspector = false
+		return m, nil
+	case "ctrl+c":
+		return m, tea.Quit
+	case "/":
+		m.inspector.searching = true
+		m.inspector.search.Focus()
+		return m, textinput.Blink
+	case "r":
+		m.inspector.loading = true
+		m.inspector.render()
+		return m, loadProperties(m.manager, m.inspector.unit)
+	case "g":
+		m.inspector.viewport.GotoTop()
+		return m, nil
+	case "G":
+		m.inspector.viewport.GotoBottom()
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.inspector.viewport, cmd = m.inspector.viewport.Update(msg)
+	return m, cmd
+}
+
+func (m model) inspectorView() string {
+	header := titleStyle.Render(fmt.Sprintf("🔎 Properties: %s", m.inspector.unit)) + " " +
+		helpStyle.Render(fmt.Sprintf("%d properties", len(m.inspector.props)))
+
+	search := helpStyle.Render("/: Search")
+	if m.inspector.searching || m.inspector.search.Value() != "" {
+		search = "🔍 " + m.inspector.search.View()
+	}
+
+	help := helpStyle.Render("j/k: Scroll | g/G: Top/Bottom | /: Search | r: Reload | q/Esc: Close")
+	return lipgloss.JoinVertical(lipgloss.Left, header, search, "", m.inspector.viewport.View(), help)
+}
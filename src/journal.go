@@ -0,0 +1,446 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	journalLines      = 1000 // entries loaded when the pane opens
+	journalMaxEntries = 5000 // entries kept while following
+)
+
+// journalRanges are the --since presets cycled with `t`.
+var journalRanges = []struct {
+	label string
+	since string
+}{
+	{"all time", ""},
+	{"last 15 minutes", "15 min ago"},
+	{"last hour", "1 hour ago"},
+	{"today", "today"},
+	{"last 24 hours", "24 hours ago"},
+}
+
+// journalPriorityStyles colors entries by syslog priority.
+var journalPriorityStyles = map[int]lipgloss.Style{
+	0: lipgloss.NewStyle().Foreground(lipgloss.Color("#FF5F87")).Bold(true), // emerg
+	1: lipgloss.NewStyle().Foreground(lipgloss.Color("#FF5F87")).Bold(true), // alert
+	2: lipgloss.NewStyle().Foreground(lipgloss.Color("#FF5F87")).Bold(true), // crit
+	3: lipgloss.NewStyle().Foreground(lipgloss.Color("#FF5F87")),            // err
+	4: lipgloss.NewStyle().Foreground(lipgloss.Color("#FFD700")),            // warning
+	5: lipgloss.NewStyle().Foreground(lipgloss.Color("#FAFAFA")).Bold(true), // notice
+	6: lipgloss.NewStyle(),                                                  // info
+	7: lipgloss.NewStyle().Foreground(lipgloss.Color("#626262")),            // debug
+}
+
+type journalEntry struct {
+	cursor     string
+	time       time.Time
+	priority   int
+	identifier string
+	pid        string
+	message    string
+}
+
+type journalBoot struct {
+	offset int
+	id     string
+}
+
+// journalFollower streams new entries from `journalctl -f`.
+type journalFollower struct {
+	cmd      *exec.Cmd
+	entries  chan journalEntry
+	done     chan struct{} // closed by stop, releases the reader
+	stopOnce sync.Once
+}
+
+func (f *journalFollower) stop() {
+	if f == nil {
+		return
+	}
+	f.stopOnce.Do(func() {
+		close(f.done)
+		if f.cmd.Process != nil {
+			f.cmd.Process.Kill()
+		}
+	})
+}
+
+// logView is the state of the journal pane opened with `l`.
+type logView struct {
+	unit       string
+	viewport   viewport.Model
+	entries    []journalEntry
+	boots      []journalBoot
+	bootOffset int  // relative to the current boot, as for journalctl -b
+	allBoots   bool // ignore bootOffset and show every boot
+	timeRange  int  // index into journalRanges
+	follower   *journalFollower
+	followed   []journalEntry // followed while loading, added once loaded
+	loading    bool
+	err        error
+}
+
+type journalLoadedMsg struct {
+	unit    string
+	entries []journalEntry
+	boots   []journalBoot
+	err     error
+}
+
+type journalEntryMsg struct {
+	follower *journalFollower
+	entry    journalEntry
+}
+
+// journalArgs builds the journalctl arguments shared by loading and following.
+func (v logView) journalArgs() []string {
+	args := []string{"-u", v.unit, "-o", "json", "--no-pager"}
+	if !v.allBoots {
+		args = append(args, "-b", strconv.Itoa(v.bootOffset))
+	}
+	if since := journalRanges[v.timeRange].since; since != "" {
+		args = append(args, "--since", since)
+	}
+	return args
+}
+
+func loadJournal(v logView) tea.Cmd {
+	args := append(v.journalArgs(), "-n", strconv.Itoa(journalLines))
+	return func() tea.Msg {
+		var stderr bytes.Buffer
+		cmd := exec.Command("journalctl", args...)
+		cmd.Stderr = &stderr
+		output, err := cmd.Output()
+		if err != nil {
+			if msg := strings.TrimSpace(stderr.String()); msg != "" {
+				err = fmt.Errorf("%w: %s", err, msg)
+			}
+			return journalLoadedMsg{unit: v.unit, err: err}
+		}
+
+		var entries []journalEntry
+		for _, line := range strings.Split(string(output), "\n") {
+			if entry, ok := parseJournalEntry([]byte(line)); ok {
+				entries = append(entries, entry)
+			}
+		}
+		return journalLoadedMsg{unit: v.unit, entries: entries, boots: listBoots()}
+	}
+}
+
+// listBoots returns the boots known to the journal, oldest first.
+func listBoots() []journalBoot {
+	output, err := exec.Command("journalctl", "--list-boots", "--no-pager").Output()
+	if err != nil {
+		return nil
+	}
+
+	var boots []journalBoot
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		offset, err := strconv.Atoi(fields[0])
+		if err != nil {
+			continue // header line
+		}
+		boots = append(boots, journalBoot{offset: offset, id: fields[1]})
+	}
+	return boots
+}
+
+// parseJournalEntry decodes one line of `journalctl -o json`.
+func parseJournalEntry(line []byte) (journalEntry, bool) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(line, &fields); err != nil {
+		return journalEntry{}, false
+	}
+
+	entry := journalEntry{
+		cursor:     journalString(fields["__CURSOR"]),
+		priority:   6,
+		identifier: journalString(fields["SYSLOG_IDENTIFIER"]),
+		pid:        journalString(fields["_PID"]),
+		message:    journalString(fields["MESSAGE"]),
+	}
+	if p, err := strconv.Atoi(journalString(fields["PRIORITY"])); err == nil {
+		entry.priority = p
+	}
+	if usec, err := strconv.ParseInt(journalString(fields["__REALTIME_TIMESTAMP"]), 10, 64); err == nil {
+		entry.time = time.UnixMicro(usec)
+	}
+	return entry, true
+}
+
+// journalString decodes a journal field, which is a string, or an array of
+// bytes when the value is not valid UTF-8.
+func journalString(raw json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+	var b []byte
+	var ints []int
+	if err := json.Unmarshal(raw, &ints); err == nil {
+		for _, i := range ints {
+			b = append(b, byte(i))
+		}
+		return string(b)
+	}
+	return ""
+}
+
+// followJournal starts `journalctl -f` for the view's unit.
+func followJournal(v logView) (*journalFollower, error) {
+	args := append(v.journalArgs(), "-f", "-n", "0")
+	cmd := exec.Command("journalctl", args...)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	f := &journalFollower{cmd: cmd, entries: make(chan journalEntry, 64), done: make(chan struct{})}
+	go func() {
+		defer close(f.entries)
+		// Reap journalctl however reading ends
+		defer cmd.Wait()
+		scanner := bufio.NewScanner(stdout)
+		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			entry, ok := parseJournalEntry(scanner.Bytes())
+			if !ok {
+				continue
+			}
+			select {
+			case f.entries <- entry:
+			case <-f.done:
+				return
+			}
+		}
+	}()
+	return f, nil
+}
+
+func waitForJournalEntry(f *journalFollower) tea.Cmd {
+	return func() tea.Msg {
+		entry, ok := <-f.entries
+		if !ok {
+			return nil
+		}
+		return journalEntryMsg{follower: f, entry: entry}
+	}
+}
+
+func (v logView) bootLabel() string {
+	if v.allBoots {
+		return "all boots"
+	}
+	if v.bootOffset == 0 {
+		return "current boot"
+	}
+	return fmt.Sprintf("boot %d", v.bootOffset)
+}
+
+// nextBoot steps back one boot at a time, then to all boots, then wraps to the
+// current boot.
+func (v *logView) nextBoot() {
+	oldest := 0
+	for _, b := range v.boots {
+		if b.offset < oldest {
+			oldest = b.offset
+		}
+	}
+	switch {
+	case v.allBoots:
+		v.allBoots, v.bootOffset = false, 0
+	case v.bootOffset > oldest:
+		v.bootOffset--
+	default:
+		v.allBoots = true
+	}
+}
+
+// render refreshes the viewport content, keeping the bottom in view when it
+// was already there.
+func (v *logView) render() {
+	atBottom := v.viewport.AtBottom()
+
+	var lines []string
+	switch {
+	case v.err != nil:
+		lines = append(lines, journalPriorityStyles[3].Render(fmt.Sprintf("Error reading journal: %v", v.err)))
+	case v.loading:
+		lines = append(lines, "Loading journal...")
+	case len(v.entries) == 0:
+		lines = append(lines, helpStyle.Render("No journal entries"))
+	}
+
+	lineStyle := lipgloss.NewStyle().MaxWidth(v.viewport.Width)
+	for _, e := range v.entries {
+		lines = append(lines, lineStyle.Render(journalPriorityStyles[e.priority].Render(formatJournalEntry(e))))
+	}
+
+	v.viewport.SetContent(strings.Join(lines, "\n"))
+	if atBottom || v.follower != nil {
+		v.viewport.GotoBottom()
+	}
+}
+
+// formatJournalEntry renders an entry like journalctl's short output.
+func formatJournalEntry(e journalEntry) string {
+	source := e.identifier
+	if e.pid != "" {
+		source += "[" + e.pid + "]"
+	}
+	return fmt.Sprintf("%s %s: %s", e.time.Format("Jan 02 15:04:05"), source, e.message)
+}
+
+// setLoaded replaces the entries with a finished load, keeping the entries
+// followed meanwhile that the load did not already return.
+func (v *logView) setLoaded(msg journalLoadedMsg) {
+	v.loading = false
+	v.entries, v.boots, v.err = msg.entries, msg.boots, msg.err
+
+	loaded := make(map[string]bool, len(msg.entries))
+	for _, e := range msg.entries {
+		loaded[e.cursor] = true
+	}
+	for _, e := range v.followed {
+		if e.cursor == "" || !loaded[e.cursor] {
+			v.entries = append(v.entries, e)
+		}
+	}
+	v.followed = nil
+	v.trim()
+	v.render()
+}
+
+// addFollowed adds an entry from follow mode, holding it back while a load
+// is running.
+func (v *logView) addFollowed(e journalEntry) {
+	if v.loading {
+		v.followed = append(v.followed, e)
+		return
+	}
+	v.entries = append(v.entries, e)
+	v.trim()
+	v.render()
+}
+
+func (v *logView) trim() {
+	if len(v.entries) > journalMaxEntries {
+		v.entries = v.entries[len(v.entries)-journalMaxEntries:]
+	}
+}
+
+// openLogs shows the journal pane for unit.
+func (m model) openLogs(unit string) (model, tea.Cmd) {
+	m.logs.follower.stop()
+	m.logs = logView{
+		unit:     unit,
+		viewport: viewport.New(m.width, m.height-4),
+		loading:  true,
+	}
+	m.logs.render()
+	m.showLogs = true
+	return m, loadJournal(m.logs)
+}
+
+// reloadLogs reloads the journal after a filter changed, restarting follow
+// mode with the new filter if it was on.
+func (m model) reloadLogs() (model, tea.Cmd) {
+	following := m.logs.follower != nil
+	m.logs.follower.stop()
+	m.logs.follower = nil
+	m.logs.followed = nil
+	m.logs.loading = true
+	m.logs.render()
+
+	cmds := []tea.Cmd{loadJournal(m.logs)}
+	if following {
+		var cmd tea.Cmd
+		m, cmd = m.toggleFollow()
+		cmds = append(cmds, cmd)
+	}
+	return m, tea.Batch(cmds...)
+}
+
+func (m model) toggleFollow() (model, tea.Cmd) {
+	if m.logs.follower != nil {
+		m.logs.follower.stop()
+		m.logs.follower = nil
+		return m, nil
+	}
+
+	f, err := followJournal(m.logs)
+	if err != nil {
+		m.message = fmt.Sprintf("❌ Failed to follow journal: %v", err)
+		return m, nil
+	}
+	m.logs.follower = f
+	m.logs.viewport.GotoBottom()
+	return m, waitForJournalEntry(f)
+}
+
+func (m model) updateLogs(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "q", "esc", "l":
+		m.logs.follower.stop()
+		m.logs.follower = nil
+		m.showLogs = false
+		return m, nil
+	case "ctrl+c":
+		m.logs.follower.stop()
+		return m, tea.Quit
+	case "b":
+		m.logs.nextBoot()
+		return m.reloadLogs()
+	case "t":
+		m.logs.timeRange = (m.logs.timeRange + 1) % len(journalRanges)
+		return m.reloadLogs()
+	case "f":
+		return m.toggleFollow()
+	case "r":
+		return m.reloadLogs()
+	case "g":
+		m.logs.viewport.GotoTop()
+		return m, nil
+	case "G":
+		m.logs.viewport.GotoBottom()
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.logs.viewport, cmd = m.logs.viewport.Update(msg)
+	return m, cmd
+}
+
+func (m model) logsView() string {
+	follow := ""
+	if m.logs.follower != nil {
+		follow = " | 📡 following"
+	}
+	header := titleStyle.Render(fmt.Sprintf("📜 Logs: %s", m.logs.unit)) + " " +
+		helpStyle.Render(fmt.Sprintf("%s | %s%s | %d entries", m.logs.bootLabel(), journalRanges[m.logs.timeRange].label, follow, len(m.logs.entries)))
+
+	help := helpStyle.Render("j/k: Scroll | g/G: Top/Bottom | b: Boot | t: Time range | f: Follow | r: Reload | q/Esc: Close")
+	return lipgloss.JoinVertical(lipgloss.Left, header, "", m.logs.viewport.View(), help)
+}
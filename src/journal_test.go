@@ -0,0 +1,130 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestParseJournalEntry(t *testing.T) {
+	tests := []struct {
+		name   string
+		line   string
+		want   journalEntry
+		wantOK bool
+	}{
+		{
+			name:   "full entry",
+			line:   `{"__CURSOR":"s=1;i=2a","__REALTIME_TIMESTAMP":"1700000000123456","PRIORITY":"3","SYSLOG_IDENTIFIER":"nginx","_PID":"812","MESSAGE":"bind() failed"}`,
+			want:   journalEntry{cursor: "s=1;i=2a", time: time.UnixMicro(1700000000123456), priority: 3, identifier: "nginx", pid: "812", message: "bind() failed"},
+			wantOK: true,
+		},
+		{
+			name:   "no priority is info",
+			line:   `{"MESSAGE":"started"}`,
+			want:   journalEntry{priority: 6, message: "started"},
+			wantOK: true,
+		},
+		{
+			name:   "unparsable priority is info",
+			line:   `{"PRIORITY":"warning","MESSAGE":"started"}`,
+			want:   journalEntry{priority: 6, message: "started"},
+			wantOK: true,
+		},
+		{
+			name:   "emerg",
+			line:   `{"PRIORITY":"0","MESSAGE":"panic"}`,
+			want:   journalEntry{priority: 0, message: "panic"},
+			wantOK: true,
+		},
+		{
+			name:   "unparsable timestamp",
+			line:   `{"__REALTIME_TIMESTAMP":"soon","MESSAGE":"started"}`,
+			want:   journalEntry{priority: 6, message: "started"},
+			wantOK: true,
+		},
+		{
+			// Not valid UTF-8, so journalctl prints the bytes
+			name:   "binary message",
+			line:   `{"MESSAGE":[104,105,255]}`,
+			want:   journalEntry{priority: 6, message: "hi\xff"},
+			wantOK: true,
+		},
+		{name: "empty line", line: ""},
+		{name: "truncated line", line: `{"MESSAGE":"sta`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseJournalEntry([]byte(tt.line))
+			if ok != tt.wantOK || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, %v, want %+v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestFormatJournalEntry(t *testing.T) {
+	at := time.Date(2024, time.March, 5, 9, 4, 5, 123456000, time.Local)
+	tests := []struct {
+		entry journalEntry
+		want  string
+	}{
+		{journalEntry{time: at, identifier: "nginx", pid: "812", message: "bind() failed"}, "Mar 05 09:04:05 nginx[812]: bind() failed"},
+		{journalEntry{time: at, identifier: "systemd", message: "Started nginx.service."}, "Mar 05 09:04:05 systemd: Started nginx.service."},
+		{journalEntry{time: at, message: "no source"}, "Mar 05 09:04:05 : no source"},
+	}
+	for _, tt := range tests {
+		if got := formatJournalEntry(tt.entry); got != tt.want {
+			t.Errorf("got %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestLogViewFollowedWhileLoading(t *testing.T) {
+	v := logView{loading: true}
+	v.addFollowed(journalEntry{cursor: "b", message: "second"})
+	v.addFollowed(journalEntry{cursor: "c", message: "third"})
+
+	// The load already returned the first followed entry
+	v.setLoaded(journalLoadedMsg{entries: []journalEntry{{cursor: "a", message: "first"}, {cursor: "b", message: "second"}}})
+
+	var got []string
+	for _, e := range v.entries {
+		got = append(got, e.message)
+	}
+	if want := []string{"first", "second", "third"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("entries %v, want %v", got, want)
+	}
+}
+
+func TestJournalFollowerStop(t *testing.T) {
+	// A journalctl that prints entries until killed
+	dir := t.TempDir()
+	script := "#!/bin/sh\nwhile :; do echo '{\"MESSAGE\":\"tick\"}'; done\n"
+	if err := os.WriteFile(filepath.Join(dir, "journalctl"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	f, err := followJournal(logView{unit: "nginx.service"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Nothing reads the entries, so the buffer fills up
+	time.Sleep(100 * time.Millisecond)
+	f.stop()
+	f.stop()
+
+	// Until waited for, the killed journalctl stays a zombie
+	pid := f.cmd.Process.Pid
+	for deadline := time.Now().Add(5 * time.Second); syscall.Kill(pid, 0) == nil; time.Sleep(10 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("journalctl not reaped after stop")
+		}
+	}
+	for range f.entries {
+	}
+}
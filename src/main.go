@@ -1,6 +1,7 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
 
@@ -8,23 +9,60 @@ import (
 )
 
 func main() {
-	// Check if running with sudo
-	if os.Geteuid() != 0 {
+	backend := flag.String("backend", "auto", "service manager backend: auto, dbus, systemctl or memory")
+	readOnly := flag.Bool("readonly", false, "disable every action that changes the system")
+	confirm := flag.String("confirm", defaultConfirm, "comma-separated actions that ask for confirmation, or none")
+	protect := flag.String("protect", defaultProtect, "comma-separated unit name patterns whose name must be typed to stop, restart, disable, mask, kill or isolate them")
+	audit := flag.Bool("audit", false, "print the action history and exit")
+	dbFlag := flag.String("db", "", "database file (default $LAZYSYS_DB, /var/lib/lazysys/lazysys.db as root, $XDG_DATA_HOME/lazysys/lazysys.db otherwise)")
+	flag.Parse()
+
+	if *audit {
+		db, err := openDatabaseReadOnly(*dbFlag)
+		if err != nil {
+			fmt.Printf("Error opening database: %v\n", err)
+			os.Exit(1)
+		}
+		err = dumpAudit(os.Stdout, db)
+		db.Close()
+		if err != nil {
+			fmt.Printf("Error reading the action history: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	manager, err := newServiceManager(*backend)
+	if err != nil {
+		fmt.Printf("Error initializing backend: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Check if running with sudo; the in-memory backend never touches the host
+	// and read-only mode only looks at it
+	if *backend != "memory" && !*readOnly && os.Geteuid() != 0 {
 		fmt.Println("❌ This application requires sudo privileges to manage systemd services.")
 		fmt.Println("Please run: sudo lazysys")
 		os.Exit(1)
 	}
 
-	db, err := initDB()
+	db, err := openDatabase(*dbFlag)
 	if err != nil {
 		fmt.Printf("Error initializing database: %v", err)
 		os.Exit(1)
 	}
 	defer db.Close()
 
-	p := tea.NewProgram(initialModel(db), tea.WithAltScreen())
-	if _, err := p.Run(); err != nil {
+	// Every action taken from here on ends up in the audit log
+	manager = newAuditingManager(manager, db)
+
+	p := tea.NewProgram(initialModel(db, manager, newSafetyConfig(*readOnly, *confirm, *protect)), tea.WithAltScreen())
+	final, err := p.Run()
+	if err != nil {
 		fmt.Printf("Error running program: %v", err)
 		os.Exit(1)
 	}
-} 
\ No newline at end of file
+	if m, ok := final.(model); ok {
+		m.stopWatchingUnits()
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"syscall"
+)
+
+// ServiceManager is the backend lazysys uses to inspect and control units.
+// The model only talks to this interface, so the systemctl implementation can
+// be swapped for another backend or for the in-memory one.
+type ServiceManager interface {
+	// ListUnits returns every unit of the given type ("service", "timer", ...)
+	// known to the manager, loaded or not, with its enablement state taken from
+	// the unit file. An empty type lists units of every type.
+	ListUnits(unitType string) ([]service, error)
+	// ServiceState returns the active state of a single unit.
+	ServiceState(name string) (string, error)
+
+	Start(name string) error
+	Stop(name string) error
+	Restart(name string) error
+	Enable(name string) error
+	Disable(name string) error
+	Mask(name string) error
+	Unmask(name string) error
+	Reload(name string) error
+	// TryRestart restarts the unit only if it is running.
+	TryRestart(name string) error
+	// ReloadOrRestart reloads the unit if it supports it, restarts it
+	// otherwise, and starts it if it is not running.
+	ReloadOrRestart(name string) error
+	// Isolate starts the target and stops every unit it does not pull in.
+	Isolate(name string) error
+	// ResetFailed clears the failed state of a unit, or of every unit when
+	// name is empty.
+	ResetFailed(name string) error
+	// Kill sends signal to every process of the unit.
+	Kill(name string, signal syscall.Signal) error
+	// DaemonReload makes systemd reread every unit file.
+	DaemonReload() error
+
+	// Properties returns every property of the unit as reported by systemd.
+	Properties(name string) (map[string]string, error)
+	// UnitsProperties returns the requested properties of several units at
+	// once, keyed by unit name.
+	UnitsProperties(names []string, props []string) (map[string]map[string]string, error)
+}
+
+func newServiceManager(backend string) (ServiceManager, error) {
+	switch backend {
+	case "auto":
+		// Prefer the bus, but keep working where it is unavailable
+		if m, err := newDBusManager(); err == nil {
+			return m, nil
+		}
+		return systemctlManager{}, nil
+	case "dbus":
+		return newDBusManager()
+	case "systemctl":
+		return systemctlManager{}, nil
+	case "memory":
+		return newMemoryManager(), nil
+	}
+	return nil, fmt.Errorf("unknown backend %q", backend)
+}
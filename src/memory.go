@@ -0,0 +1,290 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// memoryManager is an in-memory ServiceManager. It never touches the host, so
+// it can drive the UI without root or a running systemd.
+type memoryManager struct {
+	mu       sync.Mutex
+	services map[string]service
+	props    map[string]map[string]string // type-specific properties
+}
+
+// memoryActiveSubStates is the sub state a started unit of each type reports.
+var memoryActiveSubStates = map[string]string{
+	"service":   "running",
+	"timer":     "waiting",
+	"socket":    "listening",
+	"mount":     "mounted",
+	"automount": "waiting",
+	"path":      "waiting",
+}
+
+func newMemoryManager() *memoryManager {
+	m := &memoryManager{services: make(map[string]service), props: make(map[string]map[string]string)}
+	for _, s := range []service{
+		{name: "cron.service", description: "Regular background program processing daemon", loaded: "loaded", active: "active", sub: "running", enabled: "enabled"},
+		{name: "dbus.service", description: "D-Bus System Message Bus", loaded: "loaded", active: "active", sub: "running", enabled: "static"},
+		{name: "nginx.service", description: "A high performance web server", loaded: "loaded", active: "active", sub: "running", enabled: "enabled"},
+		{name: "postgresql.service", description: "PostgreSQL RDBMS", loaded: "loaded", active: "active", sub: "exited", enabled: "enabled"},
+		{name: "sshd.service", description: "OpenSSH Daemon", loaded: "loaded", active: "active", sub: "running", enabled: "enabled"},
+		{name: "bluetooth.service", description: "Bluetooth service", loaded: "loaded", active: "inactive", sub: "dead", enabled: "disabled"},
+		{name: "cups.service", description: "CUPS Scheduler", loaded: "loaded", active: "inactive", sub: "dead", enabled: "disabled"},
+		{name: "systemd-journald.service", description: "Journal Service", loaded: "loaded", active: "active", sub: "running", enabled: "static"},
+		{name: "apache2.service", description: "The Apache HTTP Server", loaded: "masked", active: "inactive", sub: "dead", enabled: "masked"},
+		{name: "backup.service", description: "Nightly backup", loaded: "loaded", active: "failed", sub: "failed", enabled: "static"},
+		{name: "redis.service", description: "Advanced key-value store", loaded: "loaded", active: "failed", sub: "failed", enabled: "enabled"},
+		{name: "logrotate.service", description: "Rotate log files", loaded: "loaded", active: "inactive", sub: "dead", enabled: "static"},
+		{name: "logrotate.timer", description: "Daily rotation of log files", loaded: "loaded", active: "active", sub: "waiting", enabled: "enabled"},
+		{name: "fstrim.timer", description: "Discard unused blocks once a week", loaded: "loaded", active: "inactive", sub: "dead", enabled: "disabled"},
+		{name: "docker.socket", description: "Docker Socket for the API", loaded: "loaded", active: "active", sub: "listening", enabled: "enabled"},
+		{name: "boot.mount", description: "/boot", loaded: "loaded", active: "active", sub: "mounted", enabled: "generated"},
+		{name: "proc-sys-fs-binfmt_misc.automount", description: "Arbitrary Executable File Formats File System Automount Point", loaded: "loaded", active: "active", sub: "waiting", enabled: "static"},
+		{name: "cups.path", description: "CUPS Scheduler", loaded: "loaded", active: "inactive", sub: "dead", enabled: "disabled"},
+		{name: "multi-user.target", description: "Multi-User System", loaded: "loaded", active: "active", sub: "active", enabled: "static"},
+		{name: "system.slice", description: "System Slice", loaded: "loaded", active: "active", sub: "active", enabled: "static"},
+	} {
+		m.services[s.name] = s
+	}
+
+	now := time.Now()
+	usec := func(t time.Time) string { return strconv.FormatInt(t.UnixMicro(), 10) }
+	m.props["logrotate.service"] = map[string]string{"Result": "success"}
+	m.props["backup.service"] = map[string]string{"Result": "exit-code", "ExecMainCode": "1", "ExecMainStatus": "1"}
+	m.props["redis.service"] = map[string]string{"Result": "signal", "ExecMainCode": "2", "ExecMainStatus": "9"}
+	m.props["logrotate.timer"] = map[string]string{"NextElapseUSecRealtime": usec(now.Add(3 * time.Hour)), "LastTriggerUSec": usec(now.Add(-21 * time.Hour)), "Unit": "logrotate.service"}
+	m.props["fstrim.timer"] = map[string]string{"Unit": "fstrim.service"}
+	m.props["docker.socket"] = map[string]string{"Listen": "/run/docker.sock (Stream)", "NConnections": "0"}
+	m.props["boot.mount"] = map[string]string{"What": "/dev/sda1", "Where": "/boot"}
+	m.props["proc-sys-fs-binfmt_misc.automount"] = map[string]string{"Where": "/proc/sys/fs/binfmt_misc"}
+	m.props["cups.path"] = map[string]string{"Paths": "PathExists=/var/cache/cups/org.cups.cupsd", "Unit": "cups.service"}
+	m.props["multi-user.target"] = map[string]string{"Wants": "cron.service nginx.service postgresql.service sshd.service redis.service", "After": "basic.target", "CanIsolate": "yes"}
+	m.props["nginx.service"] = map[string]string{"ControlGroup": "/system.slice/nginx.service", "Requires": "system.slice", "After": "network.target system.slice", "WantedBy": "multi-user.target", "CanReload": "yes"}
+	m.props["sshd.service"] = map[string]string{"Requires": "system.slice", "After": "network.target system.slice", "WantedBy": "multi-user.target", "CanReload": "yes"}
+	m.props["dbus.service"] = map[string]string{"CanReload": "yes"}
+	m.props["cron.service"] = map[string]string{"WantedBy": "multi-user.target"}
+	m.props["system.slice"] = map[string]string{"MemoryCurrent": "734003200", "TasksCurrent": "112", "CanStop": "no"}
+
+	// Accounting of the running services, see usageProperties
+	for name, usage := range map[string][]string{
+		"cron.service":             {"2416640", "1520000000", "1", "1048576", "0"},
+		"dbus.service":             {"4923392", "8310000000", "1", "3145728", "0"},
+		"nginx.service":            {"48234496", "95270000000", "5", "20971520", "5242880"},
+		"sshd.service":             {"7340032", "2200000000", "3", "8388608", "4096"},
+		"systemd-journald.service": {"31457280", "40110000000", "1", "10485760", "268435456"},
+	} {
+		if m.props[name] == nil {
+			m.props[name] = make(map[string]string)
+		}
+		for i, prop := range usageProperties {
+			m.props[name][prop] = usage[i]
+		}
+	}
+	return m
+}
+
+func (m *memoryManager) ListUnits(unitType string) ([]service, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	services := make([]service, 0, len(m.services))
+	for _, s := range m.services {
+		if unitType == "" || unitTypeOf(s.name) == unitType {
+			services = append(services, s)
+		}
+	}
+	sort.Slice(services, func(i, j int) bool { return services[i].name < services[j].name })
+	return services, nil
+}
+
+func (m *memoryManager) ServiceState(name string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s, ok := m.services[name]
+	if !ok {
+		return "", fmt.Errorf("unit %s not found", name)
+	}
+	return s.active, nil
+}
+
+// update applies fn to the named service under the lock.
+func (m *memoryManager) update(name string, fn func(s *service)) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s, ok := m.services[name]
+	if !ok {
+		return fmt.Errorf("unit %s not found", name)
+	}
+	fn(&s)
+	m.services[name] = s
+	return nil
+}
+
+func (m *memoryManager) Start(name string) error {
+	sub, ok := memoryActiveSubStates[unitTypeOf(name)]
+	if !ok {
+		sub = "active"
+	}
+	var masked error
+	err := m.update(name, func(s *service) {
+		// Like systemd, which refuses to start masked units
+		if s.loaded == "masked" {
+			masked = fmt.Errorf("unit %s is masked", name)
+			return
+		}
+		s.active, s.sub = "active", sub
+	})
+	if err != nil {
+		return err
+	}
+	return masked
+}
+
+func (m *memoryManager) Stop(name string) error {
+	return m.update(name, func(s *service) { s.active, s.sub = "inactive", "dead" })
+}
+
+func (m *memoryManager) Restart(name string) error {
+	return m.Start(name)
+}
+
+func (m *memoryManager) Enable(name string) error {
+	return m.update(name, func(s *service) { s.enabled = "enabled" })
+}
+
+func (m *memoryManager) Disable(name string) error {
+	return m.update(name, func(s *service) { s.enabled = "disabled" })
+}
+
+func (m *memoryManager) Mask(name string) error {
+	return m.update(name, func(s *service) { s.loaded, s.enabled = "masked", "masked" })
+}
+
+func (m *memoryManager) Unmask(name string) error {
+	return m.update(name, func(s *service) { s.loaded, s.enabled = "loaded", "disabled" })
+}
+
+func (m *memoryManager) Reload(name string) error {
+	props, err := m.Properties(name)
+	if err != nil {
+		return err
+	}
+	if props["CanReload"] != "yes" {
+		return fmt.Errorf("unit %s does not support reloading", name)
+	}
+	if props["ActiveState"] != "active" {
+		return fmt.Errorf("unit %s is not active", name)
+	}
+	return nil
+}
+
+func (m *memoryManager) TryRestart(name string) error {
+	state, err := m.ServiceState(name)
+	if err != nil || state != "active" {
+		return err
+	}
+	return m.Restart(name)
+}
+
+func (m *memoryManager) ReloadOrRestart(name string) error {
+	if m.Reload(name) == nil {
+		return nil
+	}
+	return m.Restart(name)
+}
+
+// Isolate only starts the target, in-memory units have no dependencies to
+// decide what to stop.
+func (m *memoryManager) Isolate(name string) error {
+	return m.Start(name)
+}
+
+func (m *memoryManager) ResetFailed(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.services[name]; name != "" && !ok {
+		return fmt.Errorf("unit %s not found", name)
+	}
+	for _, s := range m.services {
+		if (name == "" || s.name == name) && s.active == "failed" {
+			s.active, s.sub = "inactive", "dead"
+			m.services[s.name] = s
+			delete(m.props[s.name], "Result")
+		}
+	}
+	return nil
+}
+
+// Kill stops the unit for the signals that end a process by default, and
+// fails it for SIGKILL like systemd does.
+func (m *memoryManager) Kill(name string, signal syscall.Signal) error {
+	switch signal {
+	case syscall.SIGKILL:
+		return m.update(name, func(s *service) { s.active, s.sub = "failed", "failed" })
+	case syscall.SIGTERM, syscall.SIGINT, syscall.SIGQUIT, syscall.SIGHUP:
+		return m.update(name, func(s *service) { s.active, s.sub = "inactive", "dead" })
+	}
+	_, err := m.ServiceState(name)
+	return err
+}
+
+// DaemonReload has nothing to reread, units only exist in memory.
+func (m *memoryManager) DaemonReload() error {
+	return nil
+}
+
+func (m *memoryManager) Properties(name string) (map[string]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s, ok := m.services[name]
+	if !ok {
+		return nil, fmt.Errorf("unit %s not found", name)
+	}
+	canStart := "yes"
+	if s.loaded == "masked" {
+		canStart = "no"
+	}
+	props := map[string]string{
+		"Id":            s.name,
+		"Description":   s.description,
+		"LoadState":     s.loaded,
+		"ActiveState":   s.active,
+		"SubState":      s.sub,
+		"UnitFileState": s.enabled,
+		"CanStart":      canStart,
+		"CanStop":       "yes",
+		"CanReload":     "no",
+		"CanIsolate":    "no",
+	}
+	for key, value := range m.props[name] {
+		props[key] = value
+	}
+	return props, nil
+}
+
+func (m *memoryManager) UnitsProperties(names []string, props []string) (map[string]map[string]string, error) {
+	result := make(map[string]map[string]string, len(names))
+	for _, name := range names {
+		all, err := m.Properties(name)
+		if err != nil {
+			continue
+		}
+		unitProps := make(map[string]string, len(props))
+		for _, prop := range props {
+			unitProps[prop] = all[prop]
+		}
+		result[name] = unitProps
+	}
+	return result, nil
+}
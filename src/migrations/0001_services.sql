@@ -0,0 +1,6 @@
+-- Notes on units, shown and edited with U. Databases from before migrations
+-- already have this table.
+CREATE TABLE IF NOT EXISTS services (
+	name TEXT PRIMARY KEY,
+	description TEXT
+);
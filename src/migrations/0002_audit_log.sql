@@ -0,0 +1,11 @@
+-- Every action lazysys takes, see auditingManager.
+CREATE TABLE IF NOT EXISTS audit_log (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	timestamp TEXT NOT NULL,
+	user TEXT NOT NULL,
+	unit TEXT NOT NULL,
+	action TEXT NOT NULL,
+	exit_status INTEGER NOT NULL,
+	stderr TEXT NOT NULL,
+	duration_ms INTEGER NOT NULL
+);
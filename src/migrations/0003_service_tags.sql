@@ -0,0 +1,6 @@
+-- User-defined tags of units, edited with g.
+CREATE TABLE service_tags (
+	name TEXT NOT NULL,
+	tag TEXT NOT NULL,
+	PRIMARY KEY (name, tag)
+);
@@ -0,0 +1,4 @@
+-- Starred units, listed in the favorites pane. Toggled with *.
+CREATE TABLE favorites (
+	name TEXT PRIMARY KEY
+);
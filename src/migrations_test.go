@@ -0,0 +1,110 @@
+package main
+
+import (
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "lazysys.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func schemaVersion(t *testing.T, db *sql.DB) int {
+	t.Helper()
+	var version int
+	if err := db.QueryRow("SELECT MAX(version) FROM schema_version").Scan(&version); err != nil {
+		t.Fatal(err)
+	}
+	return version
+}
+
+// latestMigration is the highest version among the embedded migrations.
+func latestMigration(t *testing.T) int {
+	t.Helper()
+	migrations, err := loadMigrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return migrations[len(migrations)-1].version
+}
+
+func TestMigrateV0Database(t *testing.T) {
+	// The schema lazysys created before it had migrations
+	db := openTestDB(t)
+	if _, err := db.Exec("CREATE TABLE services (name TEXT PRIMARY KEY, description TEXT)"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec("INSERT INTO services (name, description) VALUES ('nginx.service', 'Front proxy, owned by the web team')"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := migrate(db); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := schemaVersion(t, db), latestMigration(t); got != want {
+		t.Errorf("schema version %d, want %d", got, want)
+	}
+	description, err := getServiceDescription(db, "nginx.service")
+	if err != nil || description != "Front proxy, owned by the web team" {
+		t.Errorf("description %q, %v: want the note kept", description, err)
+	}
+	for _, table := range []string{"audit_log", "service_tags", "favorites"} {
+		var name string
+		err := db.QueryRow("SELECT name FROM sqlite_master WHERE type = 'table' AND name = ?", table).Scan(&name)
+		if err != nil {
+			t.Errorf("table %s: %v", table, err)
+		}
+	}
+}
+
+func TestMigrateTwice(t *testing.T) {
+	db := openTestDB(t)
+	if err := migrate(db); err != nil {
+		t.Fatal(err)
+	}
+	if err := updateServiceDescription(db, "sshd.service", "Bastion access"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := migrate(db); err != nil {
+		t.Fatalf("second migrate: %v", err)
+	}
+	var applied int
+	if err := db.QueryRow("SELECT COUNT(*) FROM schema_version").Scan(&applied); err != nil {
+		t.Fatal(err)
+	}
+	if applied != latestMigration(t) {
+		t.Errorf("%d migrations recorded, want each applied once", applied)
+	}
+	description, err := getServiceDescription(db, "sshd.service")
+	if err != nil || description != "Bastion access" {
+		t.Errorf("description %q, %v: want the data kept", description, err)
+	}
+}
+
+func TestMigrateNewerDatabase(t *testing.T) {
+	db := openTestDB(t)
+	if err := migrate(db); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec("INSERT INTO schema_version (version, applied_at) VALUES (99, '2030-01-01T00:00:00Z')"); err != nil {
+		t.Fatal(err)
+	}
+
+	err := migrate(db)
+	if err == nil || !strings.Contains(err.Error(), "newer") {
+		t.Fatalf("migrate = %v, want the newer schema rejected", err)
+	}
+	if got := schemaVersion(t, db); got != 99 {
+		t.Errorf("schema version %d after the rejected migrate, want 99", got)
+	}
+}
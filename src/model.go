@@ -3,6 +3,7 @@ package main
 import (
 	"database/sql"
 	"fmt"
+	"os"
 	"strings"
 	"time"
 
@@ -20,25 +21,63 @@ type service struct {
 	status      string
 	loaded      string
 	active      string
+	sub         string
 	enabled     string
+	details     string // type-specific columns, see unitTypes
+	usage       string // resource usage, only set in the running pane
+	tags        string // formatted, e.g. "#web #db"; a string keeps service comparable
+	favorite    bool
+}
+
+// enablementIcons marks every UnitFileState except plain "enabled".
+var enablementIcons = map[string]string{
+	"enabled-runtime": "⏳",
+	"disabled":        "🔒",
+	"static":          "📌",
+	"masked":          "🚫",
+	"masked-runtime":  "🚫",
+	"indirect":        "🔀",
+	"generated":       "🧩",
+	"transient":       "💨",
+	"alias":           "🔖",
+	"linked":          "🔗",
+	"linked-runtime":  "🔗",
+	"bad":             "❗",
 }
 
 func (s service) Title() string {
 	statusIcon := "🔘"
-	if s.enabled == "disabled" {
-		statusIcon = "🔒"
-	} else if strings.Contains(s.active, "running") {
+	if s.active == "failed" {
+		statusIcon = "🔴"
+	} else if isRunning(s) {
 		statusIcon = "🟢"
-	} else if strings.Contains(s.active, "exited") {
+	} else if s.sub == "exited" {
 		statusIcon = "🟡"
-	} else if strings.Contains(s.active, "inactive") {
+	} else if s.active == "inactive" {
 		statusIcon = "◯"
 	}
-	return fmt.Sprintf("%s %s", statusIcon, s.name)
+	title := fmt.Sprintf("%s %s", statusIcon, s.name)
+	if icon, ok := enablementIcons[s.enabled]; ok {
+		title += " " + icon
+	}
+	if s.favorite {
+		title += " ⭐"
+	}
+	return title
 }
 
 func (s service) Description() string {
-	return s.description
+	description := s.description
+	if s.details != "" {
+		description = s.details + " · " + description
+	}
+	if s.usage != "" {
+		description = s.usage + " · " + description
+	}
+	if s.tags != "" {
+		description = s.tags + " · " + description
+	}
+	return description
 }
 
 func (s service) FilterValue() string {
@@ -47,9 +86,14 @@ func (s service) FilterValue() string {
 
 type model struct {
 	db                 *sql.DB
+	manager            ServiceManager
 	allServices        list.Model
 	runningServices    list.Model
-	focused            int // 0 = all services, 1 = running services
+	failedServices     list.Model
+	favoriteServices   list.Model
+	favorites          map[string]bool // starred unit names, nil until loaded
+	focused            int             // one of paneAll, paneRunning, paneFailed, paneFavorites
+	unitType           int             // index into unitTypes
 	loading            bool
 	spinner            spinner.Model
 	searchMode         bool
@@ -57,6 +101,14 @@ type model struct {
 	showHelp           bool
 	showAbout          bool
 	showMenu           bool
+	menuCaps           map[string]string // capabilityProperties of selectedService
+	showKillPicker     bool
+	showConfirm        bool
+	showAudit          bool
+	audit              auditView
+	confirm            confirmDialog
+	safety             safetyConfig
+	killChoice         int // index into processSignals
 	showDescription    bool
 	editingDescription bool
 	descriptionInput   textarea.Model
@@ -64,13 +116,57 @@ type model struct {
 	menuChoice         int
 	message            string
 	messageTimer       *time.Timer
+	unitChanges        <-chan []unitChange // nil until watchUnits started
+	stopWatching       chan struct{}
+	width              int
+	height             int
+	showLogs           bool
+	logs               logView
+	showInspector      bool
+	inspector          inspectorView
+	showTimers         bool
+	timers             timersView
+	showFailed         bool
+	showDeps           bool
+	deps               depsView
+	showProcesses      bool
+	processes          processView
+	showUnitFiles      bool
+	unitFiles          unitFileView
+	showOverride       bool
+	override           overrideEditor
+	lastOverride       *overrideBackup
+	showWizard         bool
+	wizard             wizard
+	pendingSelect      string // unit to select once the lists reload
+	usage              map[string]unitUsage
+	usageSort          int // index into usageSorts
+	showBulkReport     bool
+	bulkReport         bulkResultsMsg
+	tags               map[string][]string // by unit name
+	tagFilter          string              // when set, the lists only show units with this tag
+	showTagEditor      bool
+	showTagFilter      bool
+	tagInput           textinput.Model
+	showGroups         bool
+	groups             groupsView
 }
 
+// The panes of the main view, see visiblePanes for their order. The failed
+// pane is only shown once toggled with `F`, the favorites pane once a unit is
+// starred.
+const (
+	paneAll = iota
+	paneRunning
+	paneFailed
+	paneFavorites
+)
+
 type descriptionLoadedMsg struct {
 	description string
 }
 
-func initialModel(db *sql.DB) model {
+func initialModel(db *sql.DB, manager ServiceManager, safety safetyConfig) model {
 	s := spinner.New()
 	s.Spinner = spinner.Dot
 	s.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("205"))
@@ -88,6 +184,14 @@ func initialModel(db *sql.DB) model {
 	runningList.Title = "🟢 Running Services"
 	runningList.SetShowHelp(false)
 
+	failedList := list.New([]list.Item{}, list.NewDefaultDelegate(), 0, 0)
+	failedList.Title = "🔴 Failed Units"
+	failedList.SetShowHelp(false)
+
+	favoritesList := list.New([]list.Item{}, list.NewDefaultDelegate(), 0, 0)
+	favoritesList.Title = "⭐ Favorites"
+	favoritesList.SetShowHelp(false)
+
 	ta := textarea.New()
 	ta.Placeholder = "Enter a description for the service..."
 	ta.SetWidth(50)
@@ -95,9 +199,13 @@ func initialModel(db *sql.DB) model {
 
 	return model{
 		db:                 db,
+		manager:            manager,
+		safety:             safety,
 		allServices:        allList,
 		runningServices:    runningList,
-		focused:            0,
+		failedServices:     failedList,
+		favoriteServices:   favoritesList,
+		focused:            paneAll,
 		loading:            true,
 		spinner:            s,
 		searchMode:         false,
@@ -111,13 +219,19 @@ func initialModel(db *sql.DB) model {
 		selectedService:    service{},
 		menuChoice:         0,
 		message:            "",
+		stopWatching:       make(chan struct{}),
 	}
 }
 
 func (m model) Init() tea.Cmd {
 	return tea.Batch(
 		m.spinner.Tick,
-		loadServices(m.db),
+		m.loadServices(),
+		loadFailedUnits(m.manager),
+		loadTags(m.db),
+		loadFavorites(m.manager, m.db),
+		watchUnits(m.manager, m.stopWatching),
+		waitForUsageTick(),
 	)
 }
 
@@ -126,6 +240,63 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		// Confirmations can come up over any view
+		if m.showConfirm {
+			return m.updateConfirm(msg)
+		}
+		if m.showLogs {
+			return m.updateLogs(msg)
+		}
+		if m.showInspector {
+			return m.updateInspector(msg)
+		}
+		if m.showTimers {
+			return m.updateTimers(msg)
+		}
+		if m.showAudit {
+			return m.updateAudit(msg)
+		}
+		if m.showProcesses {
+			return m.updateProcesses(msg)
+		}
+		if m.showUnitFiles {
+			return m.updateUnitFiles(msg)
+		}
+		if m.showDeps {
+			return m.updateDependencies(msg)
+		}
+		if m.showGroups {
+			return m.updateGroups(msg)
+		}
+		if m.showOverride {
+			return m.updateOverride(msg)
+		}
+		if m.showWizard {
+			return m.updateWizard(msg)
+		}
+		if m.showKillPicker {
+			return m.updateKillPicker(msg)
+		}
+		if m.showMenu {
+			return m.updateMenu(msg)
+		}
+		if m.showTagEditor {
+			return m.updateTagEditor(msg)
+		}
+		if m.showTagFilter {
+			return m.updateTagFilter(msg)
+		}
+
+		if m.showBulkReport {
+			switch msg.String() {
+			case "enter", "esc", "q":
+				m.showBulkReport = false
+			case "ctrl+c":
+				return m, tea.Quit
+			}
+			return m, nil
+		}
+
 		if m.showDescription {
 			if m.editingDescription {
 				switch msg.String() {
@@ -165,38 +336,21 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.searchInput.SetValue("")
 				if searchTerm != "" {
 					// Perform search and focus
-					if m.focused == 0 {
-						found := -1
-						for i, item := range m.allServices.Items() {
-							if s, ok := item.(service); ok {
-								if strings.Contains(strings.ToLower(s.name), strings.ToLower(searchTerm)) || strings.Contains(strings.ToLower(s.description), strings.ToLower(searchTerm)) {
-									found = i
-									break
-								}
+					l := m.focusedList()
+					found := -1
+					for i, item := range l.Items() {
+						if s, ok := item.(service); ok {
+							if strings.Contains(strings.ToLower(s.name), strings.ToLower(searchTerm)) || strings.Contains(strings.ToLower(s.description), strings.ToLower(searchTerm)) {
+								found = i
+								break
 							}
 						}
-						if found >= 0 {
-							m.allServices.Select(found)
-							m.message = fmt.Sprintf("Found and focused '%s'", searchTerm)
-						} else {
-							m.message = fmt.Sprintf("No services found matching '%s'", searchTerm)
-						}
+					}
+					if found >= 0 {
+						l.Select(found)
+						m.message = fmt.Sprintf("Found and focused '%s'", searchTerm)
 					} else {
-						found := -1
-						for i, item := range m.runningServices.Items() {
-							if s, ok := item.(service); ok {
-								if strings.Contains(strings.ToLower(s.name), strings.ToLower(searchTerm)) || strings.Contains(strings.ToLower(s.description), strings.ToLower(searchTerm)) {
-									found = i
-									break
-								}
-							}
-						}
-						if found >= 0 {
-							m.runningServices.Select(found)
-							m.message = fmt.Sprintf("Found and focused '%s'", searchTerm)
-						} else {
-							m.message = fmt.Sprintf("No services found matching '%s'", searchTerm)
-						}
+						m.message = fmt.Sprintf("No services found matching '%s'", searchTerm)
 					}
 					return m, nil
 				}
@@ -215,8 +369,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		switch msg.String() {
 		case "q", "ctrl+c":
-			if m.showMenu || m.showHelp || m.showAbout || m.searchMode || m.showDescription {
-				m.showMenu = false
+			if m.showHelp || m.showAbout || m.searchMode || m.showDescription {
 				m.showHelp = false
 				m.showAbout = false
 				m.searchMode = false
@@ -225,65 +378,142 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m, tea.Quit
 			}
 		case "esc":
-			if m.showMenu || m.showHelp || m.showAbout || m.searchMode || m.showDescription {
-				m.showMenu = false
+			if m.showHelp || m.showAbout || m.searchMode || m.showDescription {
 				m.showHelp = false
 				m.showAbout = false
 				m.searchMode = false
 				m.showDescription = false
 			}
 		case "U":
-			var item list.Item
-			if m.focused == 0 {
-				item = m.allServices.SelectedItem()
-			} else {
-				item = m.runningServices.SelectedItem()
+			if s, ok := m.focusedService(); ok {
+				m.selectedService = s
+				m.showDescription = true
+				return m, loadDescriptionCommand(m.db, s.name)
 			}
-			if item != nil {
-				if s, ok := item.(service); ok {
-					m.selectedService = s
-					m.showDescription = true
-					return m, loadDescriptionCommand(m.db, s.name)
-				}
+		case "l":
+			if s, ok := m.focusedService(); ok {
+				return m.openLogs(s.name)
+			}
+		case "i":
+			if s, ok := m.focusedService(); ok {
+				return m.openInspector(s.name)
+			}
+		case "T":
+			return m.openTimers()
+		case "A":
+			return m.openAudit("")
+		case "c":
+			if s, ok := m.focusedService(); ok {
+				return m.openUnitFiles(s.name)
+			}
+		case "E":
+			if s, ok := m.focusedService(); ok {
+				return m.openOverrideEditor(s.name)
+			}
+		case "N":
+			if m.denyReadOnly("creating a service") {
+				return m, nil
+			}
+			m.wizard = newServiceWizard()
+			m.showWizard = true
+			return m, textinput.Blink
+		case "x":
+			if m.denyReadOnly("running a transient unit") {
+				return m, nil
 			}
+			m.wizard = newTransientWizard(m.manager)
+			m.showWizard = true
+			return m, textinput.Blink
+		case "Z":
+			if m.lastOverride == nil {
+				m.message = "No override edit to revert"
+				return m, nil
+			}
+			backup := *m.lastOverride
+			if m.denyReadOnly("reverting the override of " + backup.unit) {
+				return m, nil
+			}
+			// Kept until the revert succeeds, a cancelled one can be retried
+			return m.perform(pendingAction{
+				action: "revert-override",
+				label:  "Revert the last override edit of " + backup.unit,
+				units:  []string{backup.unit},
+				run:    tea.Sequence(revertOverride(m.manager, backup), m.loadServices()),
+			})
+		case "p":
+			if s, ok := m.focusedService(); ok {
+				return m.openProcesses(s.name)
+			}
+		case "o":
+			m.usageSort = (m.usageSort + 1) % len(usageSorts)
+			m.setPaneTitles()
+			m.applyUsage()
+		case "D":
+			if s, ok := m.focusedService(); ok {
+				return m.openDependencies(s)
+			}
+		case "g":
+			if s, ok := m.focusedService(); ok {
+				return m.openTagEditor(s)
+			}
+		case "#":
+			return m.openTagFilter()
+		case "G":
+			return m.openGroups()
+		case "[", "]":
+			step := 1
+			if msg.String() == "[" {
+				step = len(unitTypes) - 1
+			}
+			m.unitType = (m.unitType + step) % len(unitTypes)
+			m.setPaneTitles()
+			m.allServices.ResetSelected()
+			m.runningServices.ResetSelected()
+			return m, m.loadServices()
 		case "H":
-			m.focused = 0
+			m.focusPane(-1)
 		case "L":
-			m.focused = 1
-		case "j":
-			if m.showMenu {
-				if m.focused == 0 && m.menuChoice < 5 {
-					m.menuChoice++
-				} else if m.focused == 1 && m.menuChoice < 3 {
-					m.menuChoice++
-				}
-			} else {
-				if m.focused == 0 {
-					var cmd tea.Cmd
-					m.allServices, cmd = m.allServices.Update(msg)
-					cmds = append(cmds, cmd)
-				} else {
-					var cmd tea.Cmd
-					m.runningServices, cmd = m.runningServices.Update(msg)
-					cmds = append(cmds, cmd)
-				}
+			m.focusPane(1)
+		case "*":
+			return m.starFocused()
+		case "F":
+			m.showFailed = !m.showFailed
+			if !m.showFailed && m.focused == paneFailed {
+				m.focused = paneRunning
 			}
-		case "k":
-			if m.showMenu {
-				if m.menuChoice > 0 {
-					m.menuChoice--
-				}
-			} else {
-				if m.focused == 0 {
-					var cmd tea.Cmd
-					m.allServices, cmd = m.allServices.Update(msg)
-					cmds = append(cmds, cmd)
-				} else {
-					var cmd tea.Cmd
-					m.runningServices, cmd = m.runningServices.Update(msg)
-					cmds = append(cmds, cmd)
+			m.resizeLists()
+			if m.showFailed {
+				return m, loadFailedUnits(m.manager)
+			}
+		case "R":
+			if m.showFailed {
+				names := m.failedUnitNames()
+				if len(names) == 0 {
+					m.message = "No failed units to restart"
+					return m, nil
 				}
+				m.message = fmt.Sprintf("Restarting %d failed units...", len(names))
+				return m.perform(pendingAction{
+					action: "restart",
+					label:  fmt.Sprintf("Restart %d failed units", len(names)),
+					units:  names,
+					run:    restartUnits(m.manager, names),
+				})
 			}
+		case "C":
+			if m.showFailed {
+				return m.perform(pendingAction{
+					action: "reset-failed",
+					label:  "Reset the failed state of all units",
+					units:  m.failedUnitNames(),
+					run:    tea.Sequence(resetAllFailed(m.manager), loadFailedUnits(m.manager)),
+				})
+			}
+		case "j", "k":
+			l := m.focusedList()
+			var cmd tea.Cmd
+			*l, cmd = l.Update(msg)
+			cmds = append(cmds, cmd)
 		case "s":
 			m.searchMode = true
 			m.searchInput.Focus()
@@ -292,114 +522,264 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.showHelp = !m.showHelp
 		case "P":
 			m.showAbout = !m.showAbout
-		case "1", "2", "3", "4", "5":
-			if m.showMenu {
-				m.menuChoice = int(msg.String()[0] - '0')
-				// Execute action based on focused window and choice
-				if m.focused == 0 {
-					// All services menu
-					switch m.menuChoice {
-					case 1:
-						m.showMenu = false
-						return m, tea.Batch(executeServiceCommand(m.selectedService.name, "start"), loadServices(m.db))
-					case 2:
-						m.showMenu = false
-						return m, tea.Batch(executeServiceCommand(m.selectedService.name, "restart"), loadServices(m.db))
-					case 3:
-						m.showMenu = false
-						return m, tea.Batch(executeServiceCommand(m.selectedService.name, "stop"), loadServices(m.db))
-					case 4:
-						m.showMenu = false
-						return m, tea.Batch(executeServiceCommand(m.selectedService.name, "disable"), loadServices(m.db))
-					case 5:
-						m.showMenu = false
-						return m, tea.Batch(executeServiceCommand(m.selectedService.name, "enable"), loadServices(m.db))
-					}
-				} else {
-					// Running services menu
-					switch m.menuChoice {
-					case 1:
-						m.showMenu = false
-						return m, tea.Batch(executeServiceCommand(m.selectedService.name, "stop"), loadServices(m.db))
-					case 2:
-						m.showMenu = false
-						return m, tea.Batch(executeServiceCommand(m.selectedService.name, "restart"), loadServices(m.db))
-					case 3:
-						m.showMenu = false
-						return m, tea.Batch(executeServiceCommand(m.selectedService.name, "disable"), loadServices(m.db))
-					}
-				}
-				m.showMenu = false
-			}
 		case "enter":
-			if m.showMenu {
-				// Execute action based on focused window and choice
-				if m.focused == 0 {
-					// All services menu
-					switch m.menuChoice {
-					case 1:
-						m.showMenu = false
-						return m, tea.Batch(executeServiceCommand(m.selectedService.name, "start"), loadServices(m.db))
-					case 2:
-						m.showMenu = false
-						return m, tea.Batch(executeServiceCommand(m.selectedService.name, "restart"), loadServices(m.db))
-					case 3:
-						m.showMenu = false
-						return m, tea.Batch(executeServiceCommand(m.selectedService.name, "stop"), loadServices(m.db))
-					case 4:
-						m.showMenu = false
-						return m, tea.Batch(executeServiceCommand(m.selectedService.name, "disable"), loadServices(m.db))
-					case 5:
-						m.showMenu = false
-						return m, tea.Batch(executeServiceCommand(m.selectedService.name, "enable"), loadServices(m.db))
-					}
-				} else {
-					// Running services menu
-					switch m.menuChoice {
-					case 1:
-						m.showMenu = false
-						return m, tea.Batch(executeServiceCommand(m.selectedService.name, "stop"), loadServices(m.db))
-					case 2:
-						m.showMenu = false
-						return m, tea.Batch(executeServiceCommand(m.selectedService.name, "restart"), loadServices(m.db))
-					case 3:
-						m.showMenu = false
-						return m, tea.Batch(executeServiceCommand(m.selectedService.name, "disable"), loadServices(m.db))
-					}
-				}
-				m.showMenu = false
-			} else {
-				if m.focused == 0 {
-					if item := m.allServices.SelectedItem(); item != nil {
-						if s, ok := item.(service); ok {
-							m.selectedService = s
-							m.showMenu = true
-							m.menuChoice = 0
-						}
-					}
-				} else {
-					if item := m.runningServices.SelectedItem(); item != nil {
-						if s, ok := item.(service); ok {
-							m.selectedService = s
-							m.showMenu = true
-							m.menuChoice = 0
-						}
-					}
-				}
+			if s, ok := m.focusedService(); ok {
+				return m.openMenu(s)
 			}
 		case "r":
-			return m, loadServices(m.db)
+			return m, tea.Batch(m.loadServices(), loadFailedUnits(m.manager), loadFavorites(m.manager, m.db))
 		}
 
 	case tea.WindowSizeMsg:
-		h, v := lipgloss.NewStyle().Margin(1, 2).GetFrameSize()
-		m.allServices.SetSize(msg.Width/2-h, msg.Height-v-10)
-		m.runningServices.SetSize(msg.Width/2-h, msg.Height-v-10)
+		m.width, m.height = msg.Width, msg.Height
+		m.resizeLists()
+		m.logs.viewport.Width, m.logs.viewport.Height = msg.Width, msg.Height-4
+		m.logs.render()
+		m.inspector.viewport.Width, m.inspector.viewport.Height = msg.Width, msg.Height-5
+		m.inspector.render()
+		m.timers.table.SetWidth(msg.Width)
+		m.timers.table.SetHeight(msg.Height - 6)
+		m.audit.table.SetWidth(msg.Width)
+		m.audit.table.SetHeight(msg.Height - 6)
+		m.unitFiles.viewport.Width, m.unitFiles.viewport.Height = msg.Width, msg.Height-4
+		m.unitFiles.render()
+		m.processes.table.SetWidth(msg.Width)
+		m.processes.table.SetHeight(msg.Height - 6)
 
 	case servicesLoadedMsg:
+		if msg.unitType != m.currentUnitType().name {
+			// The user switched unit type while this was loading
+			break
+		}
 		m.loading = false
 		m.allServices.SetItems(msg.allServices)
 		m.runningServices.SetItems(msg.runningServices)
+		m.applyTags()
+		m.applyFavorites()
+		m.applyUsage()
+		if m.pendingSelect != "" {
+			for i, item := range m.allServices.Items() {
+				if s, ok := item.(service); ok && s.name == m.pendingSelect {
+					m.focused = paneAll
+					m.allServices.Select(i)
+				}
+			}
+			m.pendingSelect = ""
+		}
+		return m, loadUsage(m.manager, m.runningUnitNames())
+
+	case unitWatchStartedMsg:
+		m.unitChanges = msg.changes
+		return m, waitForUnitChanges(m.unitChanges)
+
+	case unitsChangedMsg:
+		newlyFailed := m.applyUnitChanges(msg.changes)
+		m.applyTags()
+		m.applyFavorites()
+		m.applyUsage()
+		if newlyFailed {
+			// Newly failed units need their result fetched
+			return m, tea.Batch(loadFailedUnits(m.manager), waitForUnitChanges(m.unitChanges))
+		}
+		return m, waitForUnitChanges(m.unitChanges)
+
+	case failedLoadedMsg:
+		if msg.err != nil {
+			m.message = fmt.Sprintf("❌ Error loading failed units: %v", msg.err)
+			break
+		}
+		m.failedServices.SetItems(msg.failed)
+		m.applyTags()
+		m.applyFavorites()
+
+	case tagsLoadedMsg:
+		if msg.err != nil {
+			m.message = fmt.Sprintf("❌ Error loading tags: %v", msg.err)
+			break
+		}
+		m.tags = msg.tags
+		m.applyTags()
+		m.applyFavorites()
+
+	case tagsSavedMsg:
+		if msg.err != nil {
+			m.message = fmt.Sprintf("❌ Error saving tags: %v", msg.err)
+			break
+		}
+		m.message = fmt.Sprintf("✅ Successfully tagged %s", msg.unit)
+		if len(msg.tags) == 0 {
+			m.message = fmt.Sprintf("✅ Removed the tags of %s", msg.unit)
+		}
+		if m.tagFilter != "" {
+			// The unit may have to come back into the filtered lists
+			return m, tea.Batch(loadTags(m.db), m.loadServices(), loadFailedUnits(m.manager))
+		}
+		return m, loadTags(m.db)
+
+	case groupsLoadedMsg:
+		m.groups.setGroups(msg)
+
+	case favoritesLoadedMsg:
+		m.setFavorites(msg)
+
+	case favoriteToggledMsg:
+		if msg.err != nil {
+			m.message = fmt.Sprintf("❌ Error updating favorites: %v", msg.err)
+			break
+		}
+		m.message = fmt.Sprintf("⭐ Starred %s", msg.unit)
+		if !msg.favorite {
+			m.message = fmt.Sprintf("Unstarred %s", msg.unit)
+		}
+		return m, loadFavorites(m.manager, m.db)
+
+	case bulkResultsMsg:
+		m.message = ""
+		m.bulkReport = msg
+		m.showBulkReport = true
+		return m, tea.Batch(m.loadServices(), loadFailedUnits(m.manager))
+
+	case journalLoadedMsg:
+		if m.showLogs && msg.unit == m.logs.unit {
+			m.logs.setLoaded(msg)
+		}
+
+	case journalEntryMsg:
+		if msg.follower == m.logs.follower {
+			m.logs.addFollowed(msg.entry)
+			return m, waitForJournalEntry(msg.follower)
+		}
+
+	case timersLoadedMsg:
+		m.timers.loading = false
+		m.timers.rows, m.timers.err = msg.rows, msg.err
+		m.timers.render()
+
+	case usageTickMsg:
+		return m, tea.Batch(loadUsage(m.manager, m.runningUnitNames()), waitForUsageTick())
+
+	case usageLoadedMsg:
+		// Accounting may be unavailable, the pane then simply shows no usage
+		if msg.err == nil {
+			m.setUsage(msg.usage)
+		}
+
+	case unitFilesLoadedMsg:
+		if m.showUnitFiles && msg.unit == m.unitFiles.unit {
+			m.unitFiles.loading = false
+			m.unitFiles.files, m.unitFiles.vendor, m.unitFiles.err = msg.files, msg.vendor, msg.err
+			m.unitFiles.render()
+		}
+
+	case editorFinishedMsg:
+		if msg.tmp != "" {
+			content, err := os.ReadFile(msg.tmp)
+			os.Remove(msg.tmp)
+			if err == nil && msg.err == nil {
+				m.override.input.SetValue(string(content))
+				return m.saveOverride()
+			}
+			if msg.err == nil {
+				msg.err = err
+			}
+		}
+		// Fall back to editing in the modal
+		m.override.errors = fmt.Sprintf("Editor failed: %v", msg.err)
+		m.override.input.Focus()
+
+	case overrideAppliedMsg:
+		m.override.applying = false
+		if msg.err != nil {
+			m.override.errors = strings.TrimSpace(fmt.Sprintf("❌ %v\n%s", msg.err, msg.output))
+			m.override.input.Focus()
+			break
+		}
+		m.showOverride = false
+		m.lastOverride = &msg.backup
+		m.message = fmt.Sprintf("✅ Successfully applied override of %s (Z: revert)", msg.backup.unit)
+		return m, m.loadServices()
+
+	case overrideRevertedMsg:
+		m.message = msg.text
+		if msg.reverted && m.lastOverride != nil && *m.lastOverride == msg.backup {
+			m.lastOverride = nil
+		}
+
+	case capabilitiesLoadedMsg:
+		if m.showMenu && msg.unit == m.selectedService.name {
+			m.menuCaps = msg.props
+			m.menuChoice = min(m.menuChoice, len(m.menuActions())-1)
+		}
+
+	case wizardCheckedMsg:
+		if m.showWizard && m.wizard.checking {
+			m.wizard.checking = false
+			m.wizard.checked, m.wizard.checkErr = msg.output, msg.err
+		}
+
+	case unitCreatedMsg:
+		m.wizard.writing = false
+		if msg.err != nil {
+			m.wizard.err = msg.err
+			break
+		}
+		m.showWizard = false
+		m.message = fmt.Sprintf("✅ Successfully created %s", msg.path)
+		return m, tea.Batch(m.loadServices(), loadFailedUnits(m.manager))
+
+	case transientStartedMsg:
+		m.wizard.writing = false
+		if msg.err != nil {
+			m.wizard.err = fmt.Errorf("systemd-run failed: %v %s", msg.err, msg.output)
+			break
+		}
+		m.showWizard = false
+		m.message = fmt.Sprintf("✅ Started transient unit %s", msg.unit)
+		for i, t := range unitTypes {
+			if t.name == unitTypeOf(msg.unit) && i != m.unitType {
+				m.unitType = i
+				m.setPaneTitles()
+				m.allServices.ResetSelected()
+				m.runningServices.ResetSelected()
+			}
+		}
+		m.pendingSelect = msg.unit
+		var cmd tea.Cmd
+		m, cmd = m.openLogs(msg.unit)
+		return m, tea.Batch(m.loadServices(), cmd)
+
+	case auditLoadedMsg:
+		m.audit.loading = false
+		m.audit.err = msg.err
+		m.audit.entries, m.audit.actions = msg.entries, msg.actions
+		m.audit.render()
+
+	case processesLoadedMsg:
+		if m.showProcesses && msg.unit == m.processes.unit {
+			m.processes.setProcesses(msg)
+		}
+
+	case processTickMsg:
+		if m.showProcesses && msg.opened.Equal(m.processes.opened) {
+			return m, tea.Batch(loadProcesses(m.manager, m.processes.unit), waitForProcessTick(msg.opened))
+		}
+
+	case depsLoadedMsg:
+		msg.node.loading = false
+		if msg.err != nil {
+			msg.node.expanded = false
+			m.deps.err = msg.err
+			break
+		}
+		msg.node.loaded = true
+		msg.node.children = msg.children
+
+	case propertiesLoadedMsg:
+		if m.showInspector && msg.unit == m.inspector.unit {
+			m.inspector.loading = false
+			m.inspector.props, m.inspector.err = msg.props, msg.err
+			m.inspector.render()
+		}
 
 	case descriptionLoadedMsg:
 		m.descriptionInput.SetValue(msg.description)
@@ -422,20 +802,175 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		})
 
 	default:
-		if m.focused == 0 {
-			var cmd tea.Cmd
-			m.allServices, cmd = m.allServices.Update(msg)
-			cmds = append(cmds, cmd)
-		} else {
-			var cmd tea.Cmd
-			m.runningServices, cmd = m.runningServices.Update(msg)
-			cmds = append(cmds, cmd)
-		}
+		l := m.focusedList()
+		var cmd tea.Cmd
+		*l, cmd = l.Update(msg)
+		cmds = append(cmds, cmd)
 	}
 
 	return m, tea.Batch(cmds...)
 }
 
+func (m model) currentUnitType() unitTypeInfo {
+	return unitTypes[m.unitType]
+}
+
+func (m model) loadServices() tea.Cmd {
+	return loadServices(m.manager, m.currentUnitType())
+}
+
+func (m *model) setPaneTitles() {
+	plural := m.currentUnitType().plural
+	m.allServices.Title = "📋 All " + plural
+	if m.unitType == 0 {
+		m.runningServices.Title = "🟢 Running " + plural
+	} else {
+		m.runningServices.Title = "🟢 Active " + plural
+	}
+	if m.usageSort != 0 {
+		m.runningServices.Title += " ↓ " + usageSorts[m.usageSort].label
+	}
+	m.failedServices.Title = "🔴 Failed Units"
+	if m.tagFilter != "" {
+		m.allServices.Title += " #" + m.tagFilter
+		m.runningServices.Title += " #" + m.tagFilter
+		m.failedServices.Title += " #" + m.tagFilter
+	}
+}
+
+// paneCount returns how many panes the main view currently shows.
+func (m model) paneCount() int {
+	return len(m.visiblePanes())
+}
+
+// resizeLists shares the terminal width between the visible panes.
+func (m *model) resizeLists() {
+	h, v := lipgloss.NewStyle().Margin(1, 2).GetFrameSize()
+	width, height := m.width/m.paneCount()-h, m.height-v-12
+	m.allServices.SetSize(width, height)
+	m.runningServices.SetSize(width, height)
+	m.failedServices.SetSize(width, height)
+	m.favoriteServices.SetSize(width, height)
+}
+
+// focusedList returns the list of the focused pane.
+func (m *model) focusedList() *list.Model {
+	switch m.focused {
+	case paneRunning:
+		return &m.runningServices
+	case paneFailed:
+		return &m.failedServices
+	case paneFavorites:
+		return &m.favoriteServices
+	}
+	return &m.allServices
+}
+
+// focusedService returns the service selected in the focused list.
+func (m model) focusedService() (service, bool) {
+	s, ok := m.focusedList().SelectedItem().(service)
+	return s, ok
+}
+
+func (m model) menuActions() []serviceAction {
+	if m.focused == paneFailed && m.selectedService.active == "failed" {
+		return failedUnitActions
+	}
+	return serviceActions(m.selectedService, m.focused == paneRunning, m.menuCaps)
+}
+
+// openMenu shows the action menu of s, which narrows down once the unit's
+// capabilities are loaded.
+func (m model) openMenu(s service) (model, tea.Cmd) {
+	m.selectedService = s
+	m.showMenu = true
+	m.menuChoice = 0
+	m.menuCaps = nil
+	return m, loadCapabilities(m.manager, s.name)
+}
+
+// updateMenu handles keys while the action menu is open, so that none of the
+// main view's keys act behind it.
+func (m model) updateMenu(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "q", "esc", "ctrl+c":
+		m.showMenu = false
+	case "j", "down":
+		if m.menuChoice < len(m.menuActions())-1 {
+			m.menuChoice++
+		}
+	case "k", "up":
+		if m.menuChoice > 0 {
+			m.menuChoice--
+		}
+	case "1", "2", "3", "4", "5", "6", "7", "8", "9", "0":
+		// 0 picks the tenth entry
+		m.menuChoice = (int(msg.String()[0]-'0') + 9) % 10
+		return m.runMenuAction()
+	case "enter":
+		return m.runMenuAction()
+	}
+	return m, nil
+}
+
+func (m model) updateKillPicker(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	done, picked := pickSignal(msg.String(), &m.killChoice)
+	if !done {
+		return m, nil
+	}
+	m.showKillPicker = false
+	if !picked {
+		return m, nil
+	}
+	sig := processSignals[m.killChoice]
+	unit := m.selectedService.name
+	return m.perform(pendingAction{
+		action: "kill",
+		label:  fmt.Sprintf("Send %s to every process of %s", sig.name, unit),
+		units:  []string{unit},
+		run: tea.Sequence(
+			signalUnit(m.manager, unit, sig.signal, sig.name),
+			tea.Batch(m.loadServices(), loadFailedUnits(m.manager)),
+		),
+	})
+}
+
+// runMenuAction executes the highlighted menu entry and closes the menu.
+func (m model) runMenuAction() (tea.Model, tea.Cmd) {
+	actions := m.menuActions()
+	m.showMenu = false
+	if m.menuChoice < 0 || m.menuChoice >= len(actions) {
+		return m, nil
+	}
+	action := actions[m.menuChoice]
+	unit := m.selectedService.name
+	switch action.action {
+	case "schedule":
+		if m.denyReadOnly("scheduling " + unit) {
+			return m, nil
+		}
+		m.wizard = newTimerWizard(unit)
+		m.showWizard = true
+		return m, textinput.Blink
+	case "kill":
+		if m.denyReadOnly("killing " + unit) {
+			return m, nil
+		}
+		m.showKillPicker = true
+		m.killChoice = 0
+		return m, nil
+	}
+	return m.perform(pendingAction{
+		action: action.action,
+		label:  strings.TrimSuffix(action.label, "…") + " " + unit,
+		units:  []string{unit},
+		run: tea.Sequence(
+			executeServiceCommand(m.manager, unit, action.action),
+			tea.Batch(m.loadServices(), loadFailedUnits(m.manager)),
+		),
+	})
+}
+
 func loadDescriptionCommand(db *sql.DB, serviceName string) tea.Cmd {
 	return func() tea.Msg {
 		description, err := getServiceDescription(db, serviceName)
@@ -454,4 +989,4 @@ func updateServiceDescriptionCommand(db *sql.DB, serviceName, description string
 		}
 		return messageMsg{text: "✅ Successfully updated description"}
 	}
-} 
+}
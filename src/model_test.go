@@ -0,0 +1,198 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// newTestModel builds the model on the in-memory backend and loads the lists
+// the way Init does.
+func newTestModel(t *testing.T) (model, *memoryManager) {
+	t.Helper()
+	db := openTestDB(t)
+	if err := migrate(db); err != nil {
+		t.Fatal(err)
+	}
+	manager := newMemoryManager()
+	m := initialModel(db, manager, newSafetyConfig(false, "", ""))
+	m = update(t, m, m.loadServices())
+	m = update(t, m, loadFailedUnits(m.manager))
+	return m, manager
+}
+
+// update runs cmd and feeds its message back into the model.
+func update(t *testing.T, m model, cmd tea.Cmd) model {
+	t.Helper()
+	next, _ := m.Update(cmd())
+	return next.(model)
+}
+
+func listedNames(m model) (all, running, failed []string) {
+	return serviceNames(m.allServices.Items()), serviceNames(m.runningServices.Items()), serviceNames(m.failedServices.Items())
+}
+
+func TestModelLists(t *testing.T) {
+	m, _ := newTestModel(t)
+	all, running, failed := listedNames(m)
+
+	if len(all) != 12 {
+		t.Errorf("%d services listed, want the 12 of the memory backend: %v", len(all), all)
+	}
+	for _, name := range all {
+		if unitTypeOf(name) != "service" {
+			t.Errorf("%s listed with the services", name)
+		}
+	}
+	wantRunning := []string{"cron.service", "dbus.service", "nginx.service", "sshd.service", "systemd-journald.service"}
+	if !slices.Equal(running, wantRunning) {
+		t.Errorf("running %v, want %v", running, wantRunning)
+	}
+	// The failed pane spans every unit type
+	if !slices.Equal(failed, []string{"backup.service", "redis.service"}) {
+		t.Errorf("failed %v, want backup.service and redis.service", failed)
+	}
+}
+
+func TestModelUnitTypeSwitch(t *testing.T) {
+	m, _ := newTestModel(t)
+	next, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("]")})
+	m = update(t, next.(model), cmd)
+
+	all, running, _ := listedNames(m)
+	if !slices.Equal(all, []string{"fstrim.timer", "logrotate.timer"}) {
+		t.Errorf("timers %v", all)
+	}
+	if !slices.Equal(running, []string{"logrotate.timer"}) {
+		t.Errorf("active timers %v", running)
+	}
+}
+
+func TestModelServiceCommands(t *testing.T) {
+	tests := []struct {
+		unit        string
+		action      string
+		running     bool
+		failed      bool
+		wantMessage string
+	}{
+		{"cron.service", "stop", false, false, "✅ Successfully stopped cron.service"},
+		{"bluetooth.service", "start", true, false, "✅ Successfully started bluetooth.service"},
+		{"redis.service", "restart", true, false, "✅ Successfully restarted redis.service"},
+		{"backup.service", "reset-failed", false, false, "✅ Successfully reset the failed state of backup.service"},
+		{"nginx.service", "reload", true, false, "✅ Successfully reloaded nginx.service"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.action+" "+tt.unit, func(t *testing.T) {
+			m, _ := newTestModel(t)
+			m = update(t, m, executeServiceCommand(m.manager, tt.unit, tt.action))
+			if m.message != tt.wantMessage {
+				t.Errorf("message %q, want %q", m.message, tt.wantMessage)
+			}
+
+			m = update(t, m, m.loadServices())
+			m = update(t, m, loadFailedUnits(m.manager))
+			_, running, failed := listedNames(m)
+			if slices.Contains(running, tt.unit) != tt.running {
+				t.Errorf("in running pane: %v, want %v", !tt.running, tt.running)
+			}
+			if slices.Contains(failed, tt.unit) != tt.failed {
+				t.Errorf("in failed pane: %v, want %v", !tt.failed, tt.failed)
+			}
+		})
+	}
+}
+
+func TestModelServiceCommandError(t *testing.T) {
+	m, _ := newTestModel(t)
+	// apache2 is masked
+	m = update(t, m, executeServiceCommand(m.manager, "apache2.service", "start"))
+	if !strings.HasPrefix(m.message, "❌") {
+		t.Errorf("message %q, want an error", m.message)
+	}
+}
+
+func TestServiceActions(t *testing.T) {
+	m, manager := newTestModel(t)
+	find := func(name string) service {
+		for _, item := range m.allServices.Items() {
+			if s := item.(service); s.name == name {
+				return s
+			}
+		}
+		t.Fatalf("%s not listed", name)
+		return service{}
+	}
+	actions := func(s service, runningPane bool) []string {
+		caps, err := manager.Properties(s.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, a := range serviceActions(s, runningPane, caps) {
+			names = append(names, a.action)
+		}
+		return names
+	}
+
+	tests := []struct {
+		unit        string
+		runningPane bool
+		want        []string
+		wantNot     []string
+	}{
+		{"nginx.service", true, []string{"stop", "restart", "reload", "kill", "disable"}, []string{"start", "mask", "enable"}},
+		{"cron.service", false, []string{"start", "stop", "kill", "disable", "mask", "schedule"}, []string{"reload", "enable"}},
+		{"bluetooth.service", false, []string{"start", "enable", "mask"}, []string{"disable", "kill", "reload"}},
+		{"backup.service", false, []string{"reset-failed"}, []string{"kill", "enable", "disable"}},
+		{"apache2.service", false, []string{"unmask"}, []string{"start", "enable", "mask"}},
+	}
+	for _, tt := range tests {
+		got := actions(find(tt.unit), tt.runningPane)
+		for _, action := range tt.want {
+			if !slices.Contains(got, action) {
+				t.Errorf("%s: %v lacks %s", tt.unit, got, action)
+			}
+		}
+		for _, action := range tt.wantNot {
+			if slices.Contains(got, action) {
+				t.Errorf("%s: %v offers %s", tt.unit, got, action)
+			}
+		}
+	}
+}
+
+func TestMenuKeysStayInMenu(t *testing.T) {
+	m, _ := newTestModel(t)
+	press := func(key string) {
+		t.Helper()
+		next, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+		m = next.(model)
+	}
+	m.allServices.Select(slices.Index(serviceNames(m.allServices.Items()), "nginx.service"))
+	next, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = update(t, next.(model), cmd)
+	if !m.showMenu {
+		t.Fatal("enter did not open the action menu")
+	}
+	selected := m.allServices.Index()
+
+	for _, key := range []string{"]", "[", "F", "*", "g", "#", "G", "T", "A", "H", "L", "o", "s", "r"} {
+		press(key)
+	}
+	if !m.showMenu || m.unitType != 0 || m.showFailed || m.showTagEditor || m.showTagFilter ||
+		m.showGroups || m.showTimers || m.showAudit || m.searchMode || m.focused != paneAll || m.usageSort != 0 {
+		t.Error("main view keys acted behind the menu")
+	}
+
+	press("j")
+	if m.menuChoice != 1 || m.allServices.Index() != selected {
+		t.Errorf("j moved menu choice to %d and the list to %d, want the menu only", m.menuChoice, m.allServices.Index())
+	}
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if m = next.(model); m.showMenu {
+		t.Error("esc left the menu open")
+	}
+}
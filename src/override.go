@@ -0,0 +1,291 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// systemUnitDir is where local unit files and drop-ins live.
+const systemUnitDir = "/etc/systemd/system"
+
+// overrideSections are the type-specific sections of each unit type, see
+// systemd.unit(5). Targets and devices only have [Unit] and [Install].
+var overrideSections = map[string]string{
+	"service":   "Service",
+	"socket":    "Socket",
+	"timer":     "Timer",
+	"mount":     "Mount",
+	"automount": "Automount",
+	"swap":      "Swap",
+	"path":      "Path",
+	"slice":     "Slice",
+	"scope":     "Scope",
+}
+
+// overrideTemplate is offered when unit has no override yet.
+func overrideTemplate(unit string) string {
+	if section, ok := overrideSections[unitTypeOf(unit)]; ok {
+		return "[" + section + "]\n"
+	}
+	return "[Unit]\n"
+}
+
+// overrideBackup is what an override file held before an edit, so the edit
+// can be reverted.
+type overrideBackup struct {
+	unit     string
+	path     string
+	previous string
+	existed  bool
+}
+
+// overrideEditor is the state of the override modal opened with `E`.
+type overrideEditor struct {
+	backup   overrideBackup
+	input    textarea.Model
+	errors   string // output of a failed verification
+	applying bool
+}
+
+type editorFinishedMsg struct {
+	tmp string
+	err error
+}
+
+type overrideAppliedMsg struct {
+	backup overrideBackup
+	output string
+	err    error
+}
+
+type overrideRevertedMsg struct {
+	backup   overrideBackup
+	reverted bool // the file was restored, even if daemon-reload failed
+	text     string
+}
+
+func overridePath(unit string) string {
+	return filepath.Join(systemUnitDir, unit+".d", "override.conf")
+}
+
+// readOverride returns the current override of unit and whether it exists.
+func readOverride(unit string) (overrideBackup, error) {
+	backup := overrideBackup{unit: unit, path: overridePath(unit)}
+	content, err := os.ReadFile(backup.path)
+	switch {
+	case err == nil:
+		backup.previous, backup.existed = string(content), true
+	case !errors.Is(err, os.ErrNotExist):
+		return backup, err
+	}
+	return backup, nil
+}
+
+// writeOverride replaces the override file with content, removing it and
+// its directory when content is empty.
+func writeOverride(path, content string) error {
+	if strings.TrimSpace(content) == "" {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		// Only succeeds when no other drop-in is left
+		os.Remove(filepath.Dir(path))
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(content), 0644)
+}
+
+// restoreOverride puts back what the override held before an edit.
+func restoreOverride(b overrideBackup) error {
+	if !b.existed {
+		return writeOverride(b.path, "")
+	}
+	return writeOverride(b.path, b.previous)
+}
+
+// verifyUnit runs `systemd-analyze verify`, which loads the unit with its
+// drop-ins from disk and exits non-zero on errors.
+func verifyUnit(unit string) (string, error) {
+	var output bytes.Buffer
+	cmd := exec.Command("systemd-analyze", "verify", unit)
+	cmd.Stdout, cmd.Stderr = &output, &output
+	err := cmd.Run()
+	return strings.TrimSpace(output.String()), err
+}
+
+// applyOverride writes the new override, verifies the unit and reloads the
+// daemon. A unit that fails verification, or an override systemd could not
+// reload, gets its previous override back, so the file on disk is always the
+// one systemd runs with.
+func applyOverride(manager ServiceManager, b overrideBackup, content string) tea.Cmd {
+	return func() tea.Msg {
+		output, err := auditAction(manager, b.unit, "edit-override", func() (string, error) {
+			if err := writeOverride(b.path, content); err != nil {
+				return "", err
+			}
+			output, err := verifyUnit(b.unit)
+			if err == nil {
+				if err = manager.DaemonReload(); err != nil {
+					err = fmt.Errorf("daemon-reload failed: %v", err)
+				}
+			} else {
+				err = fmt.Errorf("verification failed: %v", err)
+			}
+			if err != nil {
+				if restoreErr := restoreOverride(b); restoreErr != nil {
+					output += fmt.Sprintf("\nrestoring the previous override failed: %v", restoreErr)
+				}
+				return output, err
+			}
+			return output, nil
+		})
+		return overrideAppliedMsg{backup: b, output: output, err: err}
+	}
+}
+
+func revertOverride(manager ServiceManager, b overrideBackup) tea.Cmd {
+	return func() tea.Msg {
+		_, err := auditAction(manager, b.unit, "revert-override", func() (string, error) {
+			return "", restoreOverride(b)
+		})
+		if err != nil {
+			return overrideRevertedMsg{backup: b, text: fmt.Sprintf("❌ Failed to revert override of %s: %v", b.unit, err)}
+		}
+		if err := manager.DaemonReload(); err != nil {
+			return overrideRevertedMsg{backup: b, reverted: true, text: fmt.Sprintf("❌ Reverted override of %s but daemon-reload failed: %v", b.unit, err)}
+		}
+		return overrideRevertedMsg{backup: b, reverted: true, text: fmt.Sprintf("✅ Successfully reverted the last override edit of %s", b.unit)}
+	}
+}
+
+// editor returns the user's editor command, if any.
+func editor() []string {
+	for _, env := range []string{"VISUAL", "EDITOR"} {
+		if fields := strings.Fields(os.Getenv(env)); len(fields) > 0 {
+			return fields
+		}
+	}
+	return nil
+}
+
+// editExternally opens content in $EDITOR through a temporary file.
+func editExternally(content string) tea.Cmd {
+	tmp, err := os.CreateTemp("", "lazysys-override-*.conf")
+	if err != nil {
+		return func() tea.Msg { return editorFinishedMsg{err: err} }
+	}
+	_, err = tmp.WriteString(content)
+	tmp.Close()
+	if err != nil {
+		return func() tea.Msg { return editorFinishedMsg{tmp: tmp.Name(), err: err} }
+	}
+
+	args := append(editor(), tmp.Name())
+	return tea.ExecProcess(exec.Command(args[0], args[1:]...), func(err error) tea.Msg {
+		return editorFinishedMsg{tmp: tmp.Name(), err: err}
+	})
+}
+
+// openOverrideEditor starts editing the override of unit, in $EDITOR when
+// one is set and in the modal otherwise.
+func (m model) openOverrideEditor(unit string) (model, tea.Cmd) {
+	if m.denyReadOnly("editing the override of " + unit) {
+		return m, nil
+	}
+	backup, err := readOverride(unit)
+	if err != nil {
+		m.message = fmt.Sprintf("❌ Failed to read override of %s: %v", unit, err)
+		return m, nil
+	}
+
+	input := textarea.New()
+	input.SetWidth(70)
+	input.SetHeight(12)
+	input.ShowLineNumbers = true
+	if backup.existed {
+		input.SetValue(backup.previous)
+	} else {
+		input.SetValue(overrideTemplate(unit))
+	}
+
+	m.override = overrideEditor{backup: backup, input: input}
+	m.showOverride = true
+	if editor() != nil {
+		return m, editExternally(input.Value())
+	}
+	m.override.input.Focus()
+	return m, textarea.Blink
+}
+
+func (m model) saveOverride() (model, tea.Cmd) {
+	content := m.override.input.Value()
+	unchanged := content == m.override.backup.previous
+	if !m.override.backup.existed {
+		template := overrideTemplate(m.override.backup.unit)
+		unchanged = strings.TrimSpace(content) == strings.TrimSpace(template) || strings.TrimSpace(content) == ""
+	}
+	if unchanged {
+		m.showOverride = false
+		m.message = "No changes to the override"
+		return m, nil
+	}
+	m.override.applying = true
+	m.override.errors = ""
+	return m.perform(pendingAction{
+		action: "edit-override",
+		label:  "Apply the override of " + m.override.backup.unit,
+		units:  []string{m.override.backup.unit},
+		run:    applyOverride(m.manager, m.override.backup, content),
+	})
+}
+
+func (m model) updateOverride(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.override.applying {
+		return m, nil
+	}
+	switch msg.String() {
+	case "esc":
+		m.showOverride = false
+		return m, nil
+	case "ctrl+s":
+		return m.saveOverride()
+	case "ctrl+e":
+		if editor() != nil {
+			return m, editExternally(m.override.input.Value())
+		}
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.override.input, cmd = m.override.input.Update(msg)
+	return m, cmd
+}
+
+func (m model) overrideView() string {
+	content := fmt.Sprintf("✏️  Override: %s\n%s\n\n", m.override.backup.unit, helpStyle.Render(m.override.backup.path))
+	content += m.override.input.View() + "\n"
+
+	switch {
+	case m.override.applying:
+		content += "\nVerifying and reloading..."
+	case m.override.errors != "":
+		content += "\n" + diffRemoveStyle.Render(m.override.errors) + "\n"
+	}
+
+	help := "\nCtrl+S: Verify & apply | Esc: Cancel"
+	if editor() != nil {
+		help += " | Ctrl+E: Open $EDITOR"
+	}
+	return modalStyle.Render(content + help)
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestRevertOverrideKeptUntilReverted(t *testing.T) {
+	m, _ := newTestModel(t)
+	m.safety = newSafetyConfig(false, "revert-override", "")
+	path := filepath.Join(t.TempDir(), "nginx.service.d", "override.conf")
+	if err := writeOverride(path, "[Service]\nRestart=always\n"); err != nil {
+		t.Fatal(err)
+	}
+	backup := overrideBackup{unit: "nginx.service", path: path, previous: "[Service]\nRestart=no\n", existed: true}
+	m.lastOverride = &backup
+
+	press := func(msg tea.KeyMsg) {
+		next, _ := m.Update(msg)
+		m = next.(model)
+	}
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("Z")})
+	if !m.showConfirm {
+		t.Fatal("Z did not ask for confirmation")
+	}
+	press(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.lastOverride == nil {
+		t.Fatal("cancelling the revert forgot the backup")
+	}
+
+	// A failed revert keeps the backup too
+	broken := backup
+	broken.path = filepath.Join(path, "override.conf")
+	m = update(t, m, revertOverride(m.manager, broken))
+	if m.lastOverride == nil {
+		t.Fatal("a failed revert forgot the backup")
+	}
+
+	m = update(t, m, revertOverride(m.manager, backup))
+	if m.lastOverride != nil {
+		t.Error("backup kept after the revert succeeded")
+	}
+	if content, err := os.ReadFile(path); err != nil || string(content) != backup.previous {
+		t.Errorf("override %q, %v after revert, want %q", content, err, backup.previous)
+	}
+}
+
+// reloadFailingManager is a backend whose daemon-reload fails.
+type reloadFailingManager struct {
+	*memoryManager
+}
+
+func (reloadFailingManager) DaemonReload() error {
+	return errors.New("Failed to reload daemon: Access denied")
+}
+
+func TestApplyOverride(t *testing.T) {
+	// A systemd-analyze that finds every unit fine
+	bin := t.TempDir()
+	if err := os.WriteFile(filepath.Join(bin, "systemd-analyze"), []byte("#!/bin/sh\nexit 0\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	tests := []struct {
+		name    string
+		manager ServiceManager
+		wantErr string
+		want    string // the override on disk afterwards
+	}{
+		{"reloaded", newMemoryManager(), "", "[Service]\nRestart=always\n"},
+		{"reload failed", reloadFailingManager{newMemoryManager()}, "daemon-reload failed", "[Service]\nRestart=no\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "nginx.service.d", "override.conf")
+			backup := overrideBackup{unit: "nginx.service", path: path, previous: "[Service]\nRestart=no\n", existed: true}
+			if err := writeOverride(path, backup.previous); err != nil {
+				t.Fatal(err)
+			}
+
+			msg := applyOverride(tt.manager, backup, "[Service]\nRestart=always\n")().(overrideAppliedMsg)
+			if tt.wantErr == "" && msg.err != nil || tt.wantErr != "" && (msg.err == nil || !strings.Contains(msg.err.Error(), tt.wantErr)) {
+				t.Errorf("error %v, want %q", msg.err, tt.wantErr)
+			}
+			if content, err := os.ReadFile(path); err != nil || string(content) != tt.want {
+				t.Errorf("override %q, %v, want %q", content, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestOverrideTemplate(t *testing.T) {
+	tests := []struct {
+		unit string
+		want string
+	}{
+		{"nginx.service", "[Service]\n"},
+		{"logrotate.timer", "[Timer]\n"},
+		{"docker.socket", "[Socket]\n"},
+		{"home.mount", "[Mount]\n"},
+		{"proc-sys-fs-binfmt_misc.automount", "[Automount]\n"},
+		{"user.slice", "[Slice]\n"},
+		{"multi-user.target", "[Unit]\n"},
+		{"dev-sda.device", "[Unit]\n"},
+	}
+	for _, tt := range tests {
+		if got := overrideTemplate(tt.unit); got != tt.want {
+			t.Errorf("overrideTemplate(%q) = %q, want %q", tt.unit, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,435 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	processRefreshInterval = 2 * time.Second
+	// clockTicks is USER_HZ, the unit of the CPU times in /proc/<pid>/stat.
+	// It is 100 on every Linux architecture.
+	clockTicks = 100
+)
+
+// processSignals are offered by the signal picker.
+var processSignals = []struct {
+	name   string
+	signal syscall.Signal
+}{
+	{"SIGTERM", syscall.SIGTERM},
+	{"SIGKILL", syscall.SIGKILL},
+	{"SIGHUP", syscall.SIGHUP},
+	{"SIGINT", syscall.SIGINT},
+	{"SIGQUIT", syscall.SIGQUIT},
+	{"SIGUSR1", syscall.SIGUSR1},
+	{"SIGUSR2", syscall.SIGUSR2},
+	{"SIGSTOP", syscall.SIGSTOP},
+	{"SIGCONT", syscall.SIGCONT},
+}
+
+// processInfo is one process of a unit's control group.
+type processInfo struct {
+	pid     int
+	cgroup  string // relative to the unit's control group, "" for its root
+	user    string
+	command string
+	ticks   uint64 // user + system CPU time in clock ticks
+	rss     uint64 // resident set size in bytes
+	cpu     float64
+}
+
+type processesLoadedMsg struct {
+	unit   string
+	cgroup string
+	procs  []processInfo
+	at     time.Time
+	err    error
+}
+
+type processTickMsg struct {
+	opened time.Time
+}
+
+// processView is the state of the process view opened with `p`.
+type processView struct {
+	unit    string
+	opened  time.Time // identifies the view to its refresh ticks
+	cgroup  string
+	table   table.Model
+	procs   []processInfo
+	at      time.Time // when procs were read, for the CPU percentages
+	loading bool
+	err     error
+	// signalTarget is "pid" or "unit" while the signal picker is open
+	signalTarget string
+	signalChoice int
+}
+
+// cgroupRoot returns where the hierarchy systemd manages is mounted: the
+// unified hierarchy on cgroup v2 and hybrid systems, the named systemd one on
+// legacy systems.
+func cgroupRoot() string {
+	for _, root := range []string{"/sys/fs/cgroup", "/sys/fs/cgroup/unified", "/sys/fs/cgroup/systemd"} {
+		if _, err := os.Stat(filepath.Join(root, "cgroup.procs")); err == nil {
+			return root
+		}
+	}
+	return "/sys/fs/cgroup"
+}
+
+func loadProcesses(manager ServiceManager, unit string) tea.Cmd {
+	return func() tea.Msg {
+		props, err := manager.UnitsProperties([]string{unit}, []string{"ControlGroup"})
+		if err != nil {
+			return processesLoadedMsg{unit: unit, err: err}
+		}
+		cgroup := props[unit]["ControlGroup"]
+		if cgroup == "" {
+			return processesLoadedMsg{unit: unit, err: fmt.Errorf("%s has no control group, it is not running", unit)}
+		}
+
+		procs, err := readCgroupProcesses(filepath.Join(cgroupRoot(), cgroup))
+		return processesLoadedMsg{unit: unit, cgroup: cgroup, procs: procs, at: time.Now(), err: err}
+	}
+}
+
+// readCgroupProcesses lists the processes of a control group and of every
+// group below it.
+func readCgroupProcesses(dir string) ([]processInfo, error) {
+	users := make(map[string]string)
+	var procs []processInfo
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		data, err := os.ReadFile(filepath.Join(path, "cgroup.procs"))
+		if err != nil {
+			return nil
+		}
+		rel, _ := filepath.Rel(dir, path)
+		if rel == "." {
+			rel = ""
+		}
+		for _, field := range strings.Fields(string(data)) {
+			pid, err := strconv.Atoi(field)
+			if err != nil {
+				continue
+			}
+			// Processes may exit while we read them, keep whatever is left
+			if p, ok := readProcess(pid, users); ok {
+				p.cgroup = rel
+				procs = append(procs, p)
+			}
+		}
+		return nil
+	})
+	sort.Slice(procs, func(i, j int) bool {
+		if procs[i].cgroup != procs[j].cgroup {
+			return procs[i].cgroup < procs[j].cgroup
+		}
+		return procs[i].pid < procs[j].pid
+	})
+	return procs, err
+}
+
+// readProcess reads the command line, owner, CPU time and memory of a
+// process from /proc. users caches uid lookups.
+func readProcess(pid int, users map[string]string) (processInfo, bool) {
+	dir := filepath.Join("/proc", strconv.Itoa(pid))
+	p := processInfo{pid: pid}
+
+	status, err := os.Open(filepath.Join(dir, "status"))
+	if err != nil {
+		return p, false
+	}
+	name := ""
+	scanner := bufio.NewScanner(status)
+	for scanner.Scan() {
+		key, value, _ := strings.Cut(scanner.Text(), ":")
+		fields := strings.Fields(value)
+		switch {
+		case key == "Name" && len(fields) > 0:
+			name = fields[0]
+		case key == "Uid" && len(fields) > 0:
+			uid := fields[0]
+			if _, ok := users[uid]; !ok {
+				users[uid] = uid
+				if u, err := user.LookupId(uid); err == nil {
+					users[uid] = u.Username
+				}
+			}
+			p.user = users[uid]
+		}
+	}
+	status.Close()
+
+	if cmdline, err := os.ReadFile(filepath.Join(dir, "cmdline")); err == nil && len(cmdline) > 0 {
+		p.command = strings.TrimSpace(strings.ReplaceAll(string(cmdline), "\x00", " "))
+	} else {
+		// Kernel threads and zombies have no command line
+		p.command = "[" + name + "]"
+	}
+
+	// The command name in stat may contain spaces, so count fields after it
+	if stat, err := os.ReadFile(filepath.Join(dir, "stat")); err == nil {
+		s := string(stat)
+		fields := strings.Fields(s[strings.LastIndex(s, ")")+1:])
+		if len(fields) > 12 {
+			utime, _ := strconv.ParseUint(fields[11], 10, 64)
+			stime, _ := strconv.ParseUint(fields[12], 10, 64)
+			p.ticks = utime + stime
+		}
+	}
+	if statm, err := os.ReadFile(filepath.Join(dir, "statm")); err == nil {
+		if fields := strings.Fields(string(statm)); len(fields) > 1 {
+			pages, _ := strconv.ParseUint(fields[1], 10, 64)
+			p.rss = pages * uint64(os.Getpagesize())
+		}
+	}
+	return p, true
+}
+
+func waitForProcessTick(opened time.Time) tea.Cmd {
+	return tea.Tick(processRefreshInterval, func(time.Time) tea.Msg {
+		return processTickMsg{opened: opened}
+	})
+}
+
+// setProcesses replaces the process list, deriving each process' CPU usage
+// from the time it used since the previous read.
+func (v *processView) setProcesses(msg processesLoadedMsg) {
+	previous := make(map[int]uint64, len(v.procs))
+	for _, p := range v.procs {
+		previous[p.pid] = p.ticks
+	}
+	elapsed := msg.at.Sub(v.at).Seconds()
+	for i, p := range msg.procs {
+		if before, ok := previous[p.pid]; ok && elapsed > 0 && p.ticks >= before {
+			msg.procs[i].cpu = float64(p.ticks-before) / clockTicks / elapsed * 100
+		}
+	}
+
+	v.loading = false
+	v.cgroup, v.procs, v.at, v.err = msg.cgroup, msg.procs, msg.at, msg.err
+	v.render()
+}
+
+func newProcessTable(width, height int) table.Model {
+	command := width - 68
+	if command < 20 {
+		command = 20
+	}
+	columns := []table.Column{
+		{Title: "PID", Width: 8},
+		{Title: "USER", Width: 12},
+		{Title: "CPU%", Width: 6},
+		{Title: "RSS", Width: 8},
+		{Title: "CGROUP", Width: 20},
+		{Title: "COMMAND", Width: command},
+	}
+	return newStyledTable(columns, width, height)
+}
+
+func (v *processView) render() {
+	rows := make([]table.Row, 0, len(v.procs))
+	for _, p := range v.procs {
+		cgroup := p.cgroup
+		if cgroup == "" {
+			cgroup = "."
+		}
+		rows = append(rows, table.Row{
+			strconv.Itoa(p.pid), p.user, fmt.Sprintf("%.1f", p.cpu), formatBytes(p.rss), cgroup, p.command,
+		})
+	}
+	v.table.SetRows(rows)
+}
+
+func (v processView) selected() (processInfo, bool) {
+	cursor := v.table.Cursor()
+	if cursor < 0 || cursor >= len(v.procs) {
+		return processInfo{}, false
+	}
+	return v.procs[cursor], true
+}
+
+// openProcesses shows the processes of unit's control group.
+func (m model) openProcesses(unit string) (model, tea.Cmd) {
+	m.processes = processView{
+		unit:    unit,
+		opened:  time.Now(),
+		table:   newProcessTable(m.width, m.height-6),
+		loading: true,
+	}
+	m.showProcesses = true
+	return m, tea.Batch(loadProcesses(m.manager, unit), waitForProcessTick(m.processes.opened))
+}
+
+// signalProcess sends signal to one process of unit. It bypasses the
+// manager, so it is audited here.
+func signalProcess(manager ServiceManager, unit string, pid int, signal syscall.Signal, name string) tea.Cmd {
+	return func() tea.Msg {
+		_, err := auditAction(manager, unit, fmt.Sprintf("kill %s pid %d", name, pid), func() (string, error) {
+			return "", syscall.Kill(pid, signal)
+		})
+		if err != nil {
+			return messageMsg{text: fmt.Sprintf("❌ Failed to send %s to %d: %v", name, pid, err)}
+		}
+		return messageMsg{text: fmt.Sprintf("✅ Sent %s to %d", name, pid)}
+	}
+}
+
+func signalUnit(manager ServiceManager, unit string, signal syscall.Signal, name string) tea.Cmd {
+	return func() tea.Msg {
+		if err := manager.Kill(unit, signal); err != nil {
+			return messageMsg{text: fmt.Sprintf("❌ Failed to send %s to %s: %v", name, unit, err)}
+		}
+		return messageMsg{text: fmt.Sprintf("✅ Sent %s to every process of %s", name, unit)}
+	}
+}
+
+// pickSignal handles a key of a signal picker, moving choice. It reports
+// whether the picker closes and whether a signal was picked.
+func pickSignal(key string, choice *int) (done, picked bool) {
+	switch key {
+	case "esc", "q":
+		return true, false
+	case "j", "down":
+		if *choice < len(processSignals)-1 {
+			*choice++
+		}
+	case "k", "up":
+		if *choice > 0 {
+			*choice--
+		}
+	case "1", "2", "3", "4", "5", "6", "7", "8", "9":
+		*choice = int(key[0]-'0') - 1
+		return true, true
+	case "enter":
+		return true, true
+	}
+	return false, false
+}
+
+func (m model) updateSignalPicker(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	done, picked := pickSignal(msg.String(), &m.processes.signalChoice)
+	if !done {
+		return m, nil
+	}
+	target := m.processes.signalTarget
+	m.processes.signalTarget = ""
+	if !picked {
+		return m, nil
+	}
+	sig := processSignals[m.processes.signalChoice]
+	unit := m.processes.unit
+	if target == "unit" {
+		return m.perform(pendingAction{
+			action: "kill",
+			label:  fmt.Sprintf("Send %s to every process of %s", sig.name, unit),
+			units:  []string{unit},
+			run:    tea.Sequence(signalUnit(m.manager, unit, sig.signal, sig.name), loadProcesses(m.manager, unit)),
+		})
+	}
+	if p, ok := m.processes.selected(); ok {
+		return m.perform(pendingAction{
+			action: "kill",
+			label:  fmt.Sprintf("Send %s to %d (%s) of %s", sig.name, p.pid, p.command, unit),
+			units:  []string{unit},
+			run:    tea.Sequence(signalProcess(m.manager, unit, p.pid, sig.signal, sig.name), loadProcesses(m.manager, unit)),
+		})
+	}
+	return m, nil
+}
+
+func (m model) updateProcesses(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.processes.signalTarget != "" {
+		return m.updateSignalPicker(msg)
+	}
+
+	switch msg.String() {
+	case "q", "esc", "p":
+		m.showProcesses = false
+		return m, nil
+	case "ctrl+c":
+		return m, tea.Quit
+	case "r":
+		return m, loadProcesses(m.manager, m.processes.unit)
+	case "s":
+		if m.denyReadOnly("sending signals") {
+			return m, nil
+		}
+		if _, ok := m.processes.selected(); ok {
+			m.processes.signalTarget, m.processes.signalChoice = "pid", 0
+		}
+		return m, nil
+	case "S":
+		if m.denyReadOnly("sending signals") {
+			return m, nil
+		}
+		m.processes.signalTarget, m.processes.signalChoice = "unit", 0
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.processes.table, cmd = m.processes.table.Update(msg)
+	return m, cmd
+}
+
+func (m model) signalPickerView() string {
+	title := fmt.Sprintf("📡 Send signal to every process of %s", m.processes.unit)
+	if p, ok := m.processes.selected(); ok && m.processes.signalTarget == "pid" {
+		title = fmt.Sprintf("📡 Send signal to %d (%s)", p.pid, p.command)
+	}
+	return signalPicker(title, m.processes.signalChoice)
+}
+
+func signalPicker(title string, choice int) string {
+	content := lipgloss.NewStyle().MaxWidth(60).Render(title) + "\n\n"
+	for i, s := range processSignals {
+		line := fmt.Sprintf("%d. %s (%d)", i+1, s.name, int(s.signal))
+		if i == choice {
+			content += "▶ " + line + "\n"
+		} else {
+			content += "  " + line + "\n"
+		}
+	}
+	content += "\nEnter: Send | Esc/q: Cancel"
+	return modalStyle.Render(content)
+}
+
+func (m model) processesView() string {
+	status := fmt.Sprintf("%s | %d processes", m.processes.cgroup, len(m.processes.procs))
+	switch {
+	case m.processes.loading:
+		status = "Loading processes..."
+	case m.processes.err != nil:
+		status = fmt.Sprintf("❌ %v", m.processes.err)
+	}
+	header := titleStyle.Render(fmt.Sprintf("⚙️  Processes: %s", m.processes.unit)) + " " + helpStyle.Render(status)
+
+	help := helpStyle.Render("j/k: Navigate | s: Signal process | S: Signal whole unit (systemctl kill) | r: Reload | q/Esc: Close")
+	view := lipgloss.JoinVertical(lipgloss.Left, header, "", m.processes.table.View(), "", help)
+	if m.processes.signalTarget != "" {
+		view = dimStyle.Render(view) + "\n" + m.floatingModal(m.signalPickerView(), m.width, 15)
+	}
+	if m.message != "" {
+		view += "\n" + messageStyle.Render(m.message)
+	}
+	return view
+}
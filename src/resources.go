@@ -0,0 +1,205 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const usageRefreshInterval = 3 * time.Second
+
+// usageProperties are the cgroup accounting properties systemd exposes for
+// every unit with a control group.
+var usageProperties = []string{"MemoryCurrent", "CPUUsageNSec", "TasksCurrent", "IOReadBytes", "IOWriteBytes"}
+
+// usageSorts are the orders of the running pane, cycled with `o`.
+var usageSorts = []struct {
+	label string
+	key   func(u unitUsage) float64
+}{
+	{"name", nil},
+	{"memory", func(u unitUsage) float64 { return float64(u.memory) }},
+	{"CPU", func(u unitUsage) float64 { return u.cpu }},
+	{"tasks", func(u unitUsage) float64 { return float64(u.tasks) }},
+	{"IO", func(u unitUsage) float64 { return float64(u.ioRead + u.ioWrite) }},
+}
+
+// unitUsage is the resource usage of a unit. Counters systemd does not
+// account for are left at zero and missing from known, as is the CPU
+// percentage until two reads are available.
+type unitUsage struct {
+	memory  uint64
+	cpuNSec uint64
+	cpu     float64 // percent of one CPU since the previous refresh
+	tasks   uint64
+	ioRead  uint64
+	ioWrite uint64
+	known   map[string]bool
+	at      time.Time
+}
+
+type usageTickMsg struct{}
+
+type usageLoadedMsg struct {
+	usage map[string]unitUsage
+	err   error
+}
+
+func waitForUsageTick() tea.Cmd {
+	return tea.Tick(usageRefreshInterval, func(time.Time) tea.Msg {
+		return usageTickMsg{}
+	})
+}
+
+func loadUsage(manager ServiceManager, names []string) tea.Cmd {
+	return func() tea.Msg {
+		if len(names) == 0 {
+			return usageLoadedMsg{}
+		}
+		props, err := manager.UnitsProperties(names, usageProperties)
+		if err != nil {
+			return usageLoadedMsg{err: err}
+		}
+
+		now := time.Now()
+		usage := make(map[string]unitUsage, len(props))
+		for name, p := range props {
+			u := unitUsage{known: make(map[string]bool), at: now}
+			for _, prop := range usageProperties {
+				value, err := strconv.ParseUint(p[prop], 10, 64)
+				if err != nil || p[prop] == unsetUint64 {
+					continue
+				}
+				u.known[prop] = true
+				switch prop {
+				case "MemoryCurrent":
+					u.memory = value
+				case "CPUUsageNSec":
+					u.cpuNSec = value
+				case "TasksCurrent":
+					u.tasks = value
+				case "IOReadBytes":
+					u.ioRead = value
+				case "IOWriteBytes":
+					u.ioWrite = value
+				}
+			}
+			usage[name] = u
+		}
+		return usageLoadedMsg{usage: usage}
+	}
+}
+
+// setUsage stores freshly read usage, deriving the CPU percentage from the
+// CPU time used since the previous read.
+func (m *model) setUsage(usage map[string]unitUsage) {
+	for name, u := range usage {
+		prev, ok := m.usage[name]
+		elapsed := u.at.Sub(prev.at)
+		if ok && elapsed > 0 && u.known["CPUUsageNSec"] && u.cpuNSec >= prev.cpuNSec {
+			u.cpu = float64(u.cpuNSec-prev.cpuNSec) / float64(elapsed.Nanoseconds()) * 100
+			u.known["cpu"] = true
+		}
+		usage[name] = u
+	}
+	m.usage = usage
+	m.applyUsage()
+}
+
+// formatUsage renders the usage columns of the running pane.
+func formatUsage(u unitUsage) string {
+	value := func(prop string, format func() string) string {
+		if !u.known[prop] {
+			return "-"
+		}
+		return format()
+	}
+	return fmt.Sprintf("🧠 %s ⚡ %s 🧵 %s 💾 %s/%s",
+		value("MemoryCurrent", func() string { return formatBytes(u.memory) }),
+		value("cpu", func() string { return fmt.Sprintf("%.1f%%", u.cpu) }),
+		value("TasksCurrent", func() string { return strconv.FormatUint(u.tasks, 10) }),
+		value("IOReadBytes", func() string { return formatBytes(u.ioRead) }),
+		value("IOWriteBytes", func() string { return formatBytes(u.ioWrite) }),
+	)
+}
+
+// applyUsage fills the usage column of the running and favorites panes and
+// orders the running pane by the current sort, keeping the selection on the
+// same unit.
+func (m *model) applyUsage() {
+	for i, item := range m.favoriteServices.Items() {
+		if s, ok := item.(service); ok {
+			// A stopped favorite must not keep its last figures
+			usage := ""
+			if u, ok := m.usage[s.name]; ok && isRunning(s) {
+				usage = formatUsage(u)
+			}
+			if s.usage != usage {
+				s.usage = usage
+				m.favoriteServices.SetItem(i, s)
+			}
+		}
+	}
+
+	selected := ""
+	if s, ok := m.runningServices.SelectedItem().(service); ok {
+		selected = s.name
+	}
+
+	items := m.runningServices.Items()
+	for i, item := range items {
+		if s, ok := item.(service); ok {
+			s.usage = ""
+			if u, ok := m.usage[s.name]; ok {
+				s.usage = formatUsage(u)
+			}
+			items[i] = s
+		}
+	}
+
+	key := usageSorts[m.usageSort].key
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := items[i].(service), items[j].(service)
+		if key != nil {
+			if ka, kb := key(m.usage[a.name]), key(m.usage[b.name]); ka != kb {
+				return ka > kb
+			}
+		}
+		return a.name < b.name
+	})
+	m.runningServices.SetItems(items)
+
+	for i, item := range items {
+		if s, ok := item.(service); ok && s.name == selected {
+			m.runningServices.Select(i)
+			break
+		}
+	}
+}
+
+// runningUnitNames returns the units whose usage is shown: the running pane
+// and the running favorites.
+func (m model) runningUnitNames() []string {
+	names := serviceNames(m.runningServices.Items())
+	for _, item := range m.favoriteServices.Items() {
+		if s, ok := item.(service); ok && isRunning(s) && !slices.Contains(names, s.name) {
+			names = append(names, s.name)
+		}
+	}
+	return names
+}
+
+func serviceNames(items []list.Item) []string {
+	names := make([]string, 0, len(items))
+	for _, item := range items {
+		if s, ok := item.(service); ok {
+			names = append(names, s.name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/bubbles/list"
+)
+
+func TestApplyUsageClearsStoppedUnits(t *testing.T) {
+	newList := func(services ...service) list.Model {
+		return list.New(toListItems(services), list.NewDefaultDelegate(), 0, 0)
+	}
+	nginx := service{name: "nginx.service", active: "active", sub: "running", usage: "old figures"}
+	cron := service{name: "cron.service", active: "inactive", sub: "dead", usage: "old figures"}
+	sshd := service{name: "sshd.service", active: "active", sub: "running", usage: "old figures"}
+	usage := unitUsage{memory: 12 << 20, tasks: 3, known: map[string]bool{"MemoryCurrent": true, "TasksCurrent": true}}
+	m := model{
+		runningServices:  newList(nginx, sshd),
+		favoriteServices: newList(nginx, cron),
+		// cron stopped since the last refresh, sshd's accounting went away
+		usage: map[string]unitUsage{"nginx.service": usage, "cron.service": usage},
+	}
+
+	m.applyUsage()
+
+	got := func(l list.Model) map[string]string {
+		usages := make(map[string]string)
+		for _, item := range l.Items() {
+			usages[item.(service).name] = item.(service).usage
+		}
+		return usages
+	}
+	want := formatUsage(usage)
+	if u := got(m.favoriteServices); u["nginx.service"] != want || u["cron.service"] != "" {
+		t.Errorf("favorites usage %q, want nginx %q and none for the stopped cron", u, want)
+	}
+	if u := got(m.runningServices); u["nginx.service"] != want || u["sshd.service"] != "" {
+		t.Errorf("running usage %q, want nginx %q and none for sshd", u, want)
+	}
+}
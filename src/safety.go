@@ -0,0 +1,193 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// destructiveActions can take a unit, and whatever depends on it, down.
+var destructiveActions = map[string]bool{
+	"stop":              true,
+	"restart":           true,
+	"try-restart":       true,
+	"reload-or-restart": true,
+	"disable":           true,
+	"mask":              true,
+	"kill":              true,
+	"isolate":           true,
+}
+
+const (
+	defaultConfirm = "stop,restart,try-restart,reload-or-restart,disable,mask,kill,isolate"
+	defaultProtect = "sshd,ssh,systemd-*,dbus,dbus-broker"
+)
+
+// safetyConfig is how careful lazysys is with mutating actions, set from the
+// -readonly, -confirm and -protect flags.
+type safetyConfig struct {
+	readOnly bool
+	confirm  map[string]bool // actions that ask y/n first
+	protect  []string        // unit name patterns that need their name typed
+}
+
+func newSafetyConfig(readOnly bool, confirm, protect string) safetyConfig {
+	c := safetyConfig{readOnly: readOnly, confirm: make(map[string]bool)}
+	for _, action := range splitList(confirm) {
+		if action != "none" {
+			c.confirm[action] = true
+		}
+	}
+	c.protect = splitList(protect)
+	return c
+}
+
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// protected reports whether unit matches one of the protected patterns,
+// with or without its type suffix, so "sshd" protects sshd.service.
+func (c safetyConfig) protected(unit string) bool {
+	base := strings.TrimSuffix(unit, "."+unitTypeOf(unit))
+	for _, pattern := range c.protect {
+		for _, name := range []string{unit, base} {
+			if ok, _ := path.Match(pattern, name); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// pendingAction is a mutating action on its way through perform.
+type pendingAction struct {
+	action string // as understood by executeServiceCommand, or e.g. "create"
+	label  string // shown in the confirmation, e.g. "Stop sshd.service"
+	units  []string
+	run    tea.Cmd
+}
+
+// confirmDialog is the state of the confirmation modal.
+type confirmDialog struct {
+	pending   pendingAction
+	protected []string // when set, their names must be typed to confirm
+	input     textinput.Model
+	err       string
+}
+
+// perform is the single way mutating actions reach the system. It refuses
+// everything in read-only mode, and asks for a confirmation first when the
+// action is configured to or touches a protected unit.
+func (m model) perform(a pendingAction) (model, tea.Cmd) {
+	if m.safety.readOnly {
+		m.message = fmt.Sprintf("🔒 Read-only mode: %s is disabled", a.label)
+		return m, nil
+	}
+
+	var protected []string
+	if destructiveActions[a.action] {
+		for _, unit := range a.units {
+			if m.safety.protected(unit) {
+				protected = append(protected, unit)
+			}
+		}
+	}
+	if len(protected) == 0 && !m.safety.confirm[a.action] {
+		return m, a.run
+	}
+
+	m.confirm = confirmDialog{pending: a, protected: protected}
+	m.showConfirm = true
+	if len(protected) > 0 {
+		m.confirm.input = textinput.New()
+		m.confirm.input.Placeholder = strings.Join(protected, " ")
+		m.confirm.input.Width = 50
+		m.confirm.input.Focus()
+		return m, textinput.Blink
+	}
+	return m, nil
+}
+
+// denyReadOnly reports whether what must not start because of read-only
+// mode, for actions that open a form before reaching perform.
+func (m *model) denyReadOnly(what string) bool {
+	if m.safety.readOnly {
+		m.message = fmt.Sprintf("🔒 Read-only mode: %s is disabled", what)
+	}
+	return m.safety.readOnly
+}
+
+func (m model) updateConfirm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	c := &m.confirm
+	switch msg.String() {
+	case "esc":
+		return m.cancelConfirm(), nil
+	case "ctrl+c":
+		return m, tea.Quit
+	}
+
+	if len(c.protected) == 0 {
+		switch msg.String() {
+		case "y", "Y", "enter":
+			m.showConfirm = false
+			return m, c.pending.run
+		case "n", "N", "q":
+			return m.cancelConfirm(), nil
+		}
+		return m, nil
+	}
+
+	if msg.String() == "enter" {
+		if strings.TrimSpace(c.input.Value()) != strings.Join(c.protected, " ") {
+			c.err = "The name does not match"
+			return m, nil
+		}
+		m.showConfirm = false
+		return m, c.pending.run
+	}
+	var cmd tea.Cmd
+	c.input, cmd = c.input.Update(msg)
+	return m, cmd
+}
+
+// cancelConfirm drops the pending action, leaving the forms that were
+// waiting on it editable again.
+func (m model) cancelConfirm() model {
+	m.showConfirm = false
+	m.wizard.writing = false
+	m.override.applying = false
+	m.message = fmt.Sprintf("Cancelled: %s", m.confirm.pending.label)
+	return m
+}
+
+func (m model) confirmView() string {
+	c := m.confirm
+	content := fmt.Sprintf("⚠️  %s?\n\n", c.pending.label)
+	if len(c.pending.units) > 1 {
+		content += helpStyle.Render(strings.Join(c.pending.units, ", ")) + "\n\n"
+	}
+	if len(c.protected) == 0 {
+		return modalStyle.Render(content + "y/Enter: Confirm | n/Esc: Cancel")
+	}
+
+	noun := "is a protected unit"
+	if len(c.protected) > 1 {
+		noun = "are protected units"
+	}
+	content += diffRemoveStyle.Render(fmt.Sprintf("%s %s.", strings.Join(c.protected, " "), noun)) + "\n"
+	content += "Type the name to confirm:\n\n" + c.input.View() + "\n"
+	if c.err != "" {
+		content += "\n" + diffRemoveStyle.Render("❌ "+c.err) + "\n"
+	}
+	return modalStyle.Render(content + "\nEnter: Confirm | Esc: Cancel")
+}
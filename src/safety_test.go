@@ -0,0 +1,179 @@
+package main
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestSafetyProtected(t *testing.T) {
+	c := newSafetyConfig(false, defaultConfirm, defaultProtect)
+	tests := []struct {
+		unit string
+		want bool
+	}{
+		{"sshd.service", true},
+		{"ssh.service", true},
+		{"ssh.socket", true}, // "ssh" protects every type
+		{"systemd-journald.service", true},
+		{"systemd-logind.service", true},
+		{"systemd-tmpfiles-clean.timer", true},
+		{"dbus.service", true},
+		{"dbus.socket", true},
+		{"dbus-broker.service", true},
+		{"nginx.service", false},
+		{"sshd-keygen.service", false},
+		{"dbus-org.freedesktop.resolve1.service", false},
+		{"my-systemd-helper.service", false},
+		{"getty@tty1.service", false},
+	}
+	for _, tt := range tests {
+		if got := c.protected(tt.unit); got != tt.want {
+			t.Errorf("protected(%q) = %v, want %v", tt.unit, got, tt.want)
+		}
+	}
+
+	if newSafetyConfig(false, defaultConfirm, "").protected("sshd.service") {
+		t.Error("sshd.service protected without patterns")
+	}
+	if c := newSafetyConfig(false, "", " nginx , *.mount "); !c.protected("nginx.service") || !c.protected("home.mount") {
+		t.Errorf("patterns %q: want nginx.service and home.mount protected", c.protect)
+	}
+}
+
+type performedMsg struct{}
+
+func performed() tea.Msg { return performedMsg{} }
+
+func TestPerform(t *testing.T) {
+	tests := []struct {
+		name          string
+		confirm       string
+		action        string
+		units         []string
+		wantRun       bool
+		wantProtected []string
+	}{
+		{"start", defaultConfirm, "start", []string{"nginx.service"}, true, nil},
+		{"stop asks", defaultConfirm, "stop", []string{"nginx.service"}, false, nil},
+		{"stop without confirmations", "none", "stop", []string{"nginx.service"}, true, nil},
+		{"confirmed non-destructive action", "start,enable", "enable", []string{"nginx.service"}, false, nil},
+		{"restart of a protected unit", defaultConfirm, "restart", []string{"sshd.service"}, false, []string{"sshd.service"}},
+		{"protected without confirmations", "none", "kill", []string{"dbus.socket"}, false, []string{"dbus.socket"}},
+		{"start of a protected unit", defaultConfirm, "start", []string{"sshd.service"}, true, nil},
+		{"bulk restart", "none", "restart", []string{"nginx.service", "systemd-journald.service", "cron.service"}, false, []string{"systemd-journald.service"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := model{safety: newSafetyConfig(false, tt.confirm, defaultProtect)}
+			m, cmd := m.perform(pendingAction{action: tt.action, label: tt.name, units: tt.units, run: performed})
+
+			ran := cmd != nil && reflect.DeepEqual(cmd(), performedMsg{})
+			if ran != tt.wantRun || m.showConfirm == tt.wantRun {
+				t.Errorf("ran %v with confirmation %v, want run %v", ran, m.showConfirm, tt.wantRun)
+			}
+			if !reflect.DeepEqual(m.confirm.protected, tt.wantProtected) {
+				t.Errorf("protected %v, want %v", m.confirm.protected, tt.wantProtected)
+			}
+		})
+	}
+}
+
+func TestConfirmDialog(t *testing.T) {
+	m := model{safety: newSafetyConfig(false, defaultConfirm, defaultProtect)}
+	key := func(m model, k string) (model, tea.Cmd) {
+		msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+		switch k {
+		case "enter":
+			msg = tea.KeyMsg{Type: tea.KeyEnter}
+		case "esc":
+			msg = tea.KeyMsg{Type: tea.KeyEsc}
+		}
+		next, cmd := m.updateConfirm(msg)
+		return next.(model), cmd
+	}
+
+	asked, _ := m.perform(pendingAction{action: "stop", label: "Stop nginx.service", units: []string{"nginx.service"}, run: performed})
+	if next, cmd := key(asked, "n"); next.showConfirm || cmd != nil {
+		t.Error("n did not cancel")
+	}
+	if next, cmd := key(asked, "y"); next.showConfirm || cmd == nil {
+		t.Error("y did not run the action")
+	}
+
+	asked, _ = m.perform(pendingAction{action: "stop", label: "Stop sshd.service", units: []string{"sshd.service"}, run: performed})
+	// Typed into the name instead
+	if next, _ := key(asked, "y"); !next.showConfirm || next.confirm.input.Value() != "y" {
+		t.Error("y confirmed a protected unit")
+	}
+	typed := asked
+	for _, r := range "sshd" {
+		typed, _ = key(typed, string(r))
+	}
+	if next, cmd := key(typed, "enter"); !next.showConfirm || cmd != nil || next.confirm.err == "" {
+		t.Error("a partial name confirmed a protected unit")
+	}
+	for _, r := range ".service" {
+		typed, _ = key(typed, string(r))
+	}
+	if next, cmd := key(typed, "enter"); next.showConfirm || cmd == nil {
+		t.Error("the typed name did not confirm")
+	}
+	if next, cmd := key(typed, "esc"); next.showConfirm || cmd != nil {
+		t.Error("esc did not cancel")
+	}
+}
+
+func TestReadOnlyBlocksEveryAction(t *testing.T) {
+	m := model{safety: newSafetyConfig(true, "none", "")}
+	actions := []string{
+		"start", "stop", "restart", "reload", "try-restart", "reload-or-restart", "enable", "disable",
+		"mask", "unmask", "kill", "isolate", "reset-failed", "create", "schedule", "run-transient",
+		"edit-override", "revert-override", "signal",
+	}
+	for _, action := range actions {
+		next, cmd := m.perform(pendingAction{action: action, label: action, units: []string{"nginx.service"}, run: performed})
+		if cmd != nil || next.showConfirm || !strings.Contains(next.message, "Read-only") {
+			t.Errorf("%s: ran or asked in read-only mode, message %q", action, next.message)
+		}
+	}
+}
+
+func TestReadOnlyForms(t *testing.T) {
+	m, _ := newTestModel(t)
+	m.safety = newSafetyConfig(true, defaultConfirm, defaultProtect)
+	m.lastOverride = &overrideBackup{unit: "nginx.service"}
+
+	// The forms that only reach perform once filled in do not open, the bulk
+	// actions of the failed pane and every menu entry are refused
+	m.showFailed = true
+	for _, key := range []string{"N", "x", "E", "Z", "R", "C"} {
+		next, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+		got := next.(model)
+		if got.showWizard || got.showOverride || got.showConfirm || cmd != nil || !strings.Contains(got.message, "Read-only") {
+			t.Errorf("%s opened in read-only mode, message %q", key, got.message)
+		}
+	}
+}
+
+func TestReadOnlyMenuActions(t *testing.T) {
+	m, _ := newTestModel(t)
+	m.safety = newSafetyConfig(true, defaultConfirm, defaultProtect)
+	for _, unit := range []string{"nginx.service", "bluetooth.service", "backup.service", "apache2.service"} {
+		m.allServices.Select(slices.Index(serviceNames(m.allServices.Items()), unit))
+		next, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		opened := update(t, next.(model), cmd)
+
+		for i, action := range opened.menuActions() {
+			opened.menuChoice = i
+			next, cmd := opened.Update(tea.KeyMsg{Type: tea.KeyEnter})
+			got := next.(model)
+			if got.showWizard || got.showKillPicker || got.showConfirm || cmd != nil || !strings.Contains(got.message, "Read-only") {
+				t.Errorf("%s of %s went ahead in read-only mode, message %q", action.action, unit, got.message)
+			}
+		}
+	}
+}
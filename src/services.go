@@ -1,17 +1,16 @@
 package main
 
 import (
-	"database/sql"
 	"fmt"
-	"os/exec"
+	"sort"
 	"strings"
-	"time"
 
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
 type servicesLoadedMsg struct {
+	unitType        string
 	allServices     []list.Item
 	runningServices []list.Item
 }
@@ -20,224 +19,257 @@ type messageMsg struct {
 	text string
 }
 
-func loadServices(db *sql.DB) tea.Cmd {
+func loadServices(manager ServiceManager, info unitTypeInfo) tea.Cmd {
 	return func() tea.Msg {
-		allServices, err := getAllServices(db)
+		allServices, err := manager.ListUnits(info.name)
 		if err != nil {
-			return messageMsg{text: fmt.Sprintf("Error loading all services: %v", err)}
-		}
-
-		runningServices, err := getRunningServices(db)
-		if err != nil {
-			return messageMsg{text: fmt.Sprintf("Error loading running services: %v", err)}
+			return messageMsg{text: fmt.Sprintf("Error loading all %s: %v", strings.ToLower(info.plural), err)}
 		}
+		addUnitDetails(manager, info, allServices)
 
 		return servicesLoadedMsg{
-			allServices:     allServices,
-			runningServices: runningServices,
+			unitType:        info.name,
+			allServices:     toListItems(allServices),
+			runningServices: toListItems(runningServices(allServices)),
 		}
 	}
 }
 
-func getAllServices(db *sql.DB) ([]list.Item, error) {
-	// Get all loaded services
-	cmd := exec.Command("systemctl", "list-units", "--type=service", "--all")
-	output, err := cmd.Output()
-	if err != nil {
-		return nil, err
+// isRunning reports whether s belongs in the running pane: running services,
+// or active units of any other type.
+func isRunning(s service) bool {
+	if unitTypeOf(s.name) == "service" {
+		return s.sub == "running"
+	}
+	return s.active == "active"
+}
+
+func runningServices(services []service) []service {
+	var running []service
+	for _, s := range services {
+		if isRunning(s) {
+			running = append(running, s)
+		}
 	}
+	return running
+}
 
+// mergeUnitFileStates sets each unit's enablement from its unit file state and
+// adds rows for unit files of the given type (any type if empty) that are not
+// loaded. Templates are skipped since they cannot be acted on directly; their
+// instances inherit the template's state.
+func mergeUnitFileStates(units []service, states map[string]string, unitType string) []service {
 	servicesMap := make(map[string]service)
-	lines := strings.Split(string(output), "\n")
-	for _, line := range lines {
-		fields := strings.Fields(line)
-		if len(fields) == 0 {
-			continue
+	for _, s := range units {
+		s.enabled = states[s.name]
+		if at := strings.Index(s.name, "@"); s.enabled == "" && at >= 0 {
+			s.enabled = states[s.name[:at+1]+s.name[strings.LastIndex(s.name, "."):]]
 		}
-		name := fields[0]
-		if !strings.HasSuffix(name, ".service") {
+		servicesMap[s.name] = s
+	}
+	for name, state := range states {
+		if (unitType != "" && unitTypeOf(name) != unitType) || strings.Contains(name, "@.") {
 			continue
 		}
-		loaded := ""
-		active := ""
-		description := ""
-		if len(fields) > 1 {
-			loaded = fields[1]
-		}
-		if len(fields) > 2 {
-			active = fields[2]
-		}
-		if len(fields) > 3 {
-			description = strings.Join(fields[3:], " ")
+		if _, ok := servicesMap[name]; ok {
+			continue
 		}
 		servicesMap[name] = service{
-			name:        name,
-			description: description,
-			loaded:      loaded,
-			active:      active,
-			enabled:     "enabled", // default, will update below if found disabled
-		}
-	}
-
-	// Get all disabled services
-	disabledCmd := exec.Command("systemctl", "list-unit-files", "--type=service", "--state=disabled", "--no-legend")
-	disabledOutput, err := disabledCmd.Output()
-	if err == nil {
-		disabledLines := strings.Split(string(disabledOutput), "\n")
-		for _, line := range disabledLines {
-			fields := strings.Fields(line)
-			if len(fields) == 0 {
-				continue
-			}
-			name := fields[0]
-			if !strings.HasSuffix(name, ".service") {
-				continue
-			}
-			if s, ok := servicesMap[name]; ok {
-				s.enabled = "disabled"
-				servicesMap[name] = s
-			} else {
-				servicesMap[name] = service{
-					name:    name,
-					loaded:  "",
-					active:  "",
-					description: "",
-					enabled: "disabled",
-				}
-			}
+			name:    name,
+			active:  "inactive",
+			sub:     "dead",
+			enabled: state,
 		}
 	}
+	return sortedServices(servicesMap)
+}
 
-	// Convert map to slice
-	var services []list.Item
+// sortedServices flattens a name-keyed set of services into a slice sorted by
+// name, so list order is stable across reloads.
+func sortedServices(servicesMap map[string]service) []service {
+	services := make([]service, 0, len(servicesMap))
 	for _, s := range servicesMap {
 		services = append(services, s)
 	}
-
-	return services, nil
+	sort.Slice(services, func(i, j int) bool { return services[i].name < services[j].name })
+	return services
 }
 
-func getRunningServices(db *sql.DB) ([]list.Item, error) {
-	cmd := exec.Command("systemctl", "list-units", "--type=service", "--state=running")
-	output, err := cmd.Output()
-	if err != nil {
-		return nil, err
+func toListItems(services []service) []list.Item {
+	items := make([]list.Item, 0, len(services))
+	for _, s := range services {
+		items = append(items, s)
 	}
+	return items
+}
 
-	var services []list.Item
-	lines := strings.Split(string(output), "\n")
-	for _, line := range lines {
-		fields := strings.Fields(line)
-		if len(fields) == 0 || fields[0] == "UNIT" || fields[0] == "" {
-			continue
-		}
-		// Skip legend and summary lines
-		if strings.HasPrefix(line, "Legend:") ||
-			strings.Contains(line, "loaded units listed") ||
-			fields[0] == "LOAD" || fields[0] == "ACTIVE" || fields[0] == "SUB" {
-			continue
-		}
-			name := fields[0]
-		loaded := ""
-		active := ""
-		description := ""
-		if len(fields) > 1 {
-			loaded = fields[1]
-		}
-		if len(fields) > 2 {
-			active = fields[2]
-		}
-		if len(fields) > 3 {
-			description = strings.Join(fields[3:], " ")
-		}
-			services = append(services, service{
-				name:        name,
-				description: description,
-				loaded:      loaded,
-				active:      active,
-			})
-	}
+// serviceAction is one entry of the action menu.
+type serviceAction struct {
+	label  string
+	action string // as understood by executeServiceCommand
+}
 
-	return services, nil
+// capabilityProperties decide which actions a unit supports. They are loaded
+// when the action menu opens.
+var capabilityProperties = []string{"CanStart", "CanStop", "CanReload", "CanIsolate"}
+
+// killableTypes are the unit types that own processes to send signals to.
+var killableTypes = map[string]bool{"service": true, "socket": true, "mount": true, "swap": true, "scope": true}
+
+type capabilitiesLoadedMsg struct {
+	unit  string
+	props map[string]string
 }
 
-func showAllServicesMenu(item list.Item) tea.Cmd {
+func loadCapabilities(manager ServiceManager, unit string) tea.Cmd {
 	return func() tea.Msg {
-		if item == nil {
-			return messageMsg{text: "No service selected"}
-		}
-		
-		s, ok := item.(service)
-		if !ok {
-			return messageMsg{text: "Invalid service item"}
+		props, err := manager.UnitsProperties([]string{unit}, capabilityProperties)
+		if err != nil {
+			return capabilitiesLoadedMsg{unit: unit}
 		}
-
-		// For now, we'll just execute a default action
-		// In a full implementation, you'd want to show a proper TUI menu
-		return executeServiceCommand(s.name, "status")
+		return capabilitiesLoadedMsg{unit: unit, props: props[unit]}
 	}
 }
 
-func showRunningServicesMenu(item list.Item) tea.Cmd {
-	return func() tea.Msg {
-		if item == nil {
-			return messageMsg{text: "No service selected"}
-		}
-		
-		s, ok := item.(service)
-		if !ok {
-			return messageMsg{text: "Invalid service item"}
-		}
+// serviceActions returns the actions offered for s, worded for its unit type.
+// The running pane keeps its shorter menu, and enable/disable only appear when
+// the unit file state allows them. caps holds the capabilityProperties of s;
+// until they are loaded, starting and stopping are assumed to be allowed.
+func serviceActions(s service, runningPane bool, caps map[string]string) []serviceAction {
+	if s.enabled == "masked" || s.enabled == "masked-runtime" {
+		return []serviceAction{{"Unmask", "unmask"}}
+	}
 
-		// For now, we'll just execute a default action
-		// In a full implementation, you'd want to show a proper TUI menu
-		return executeServiceCommand(s.name, "status")
+	can := func(prop string, fallback bool) bool {
+		if v := caps[prop]; v != "" {
+			return v == "yes"
+		}
+		return fallback
 	}
-}
+	canStart, canStop := can("CanStart", true), can("CanStop", true)
+	active := s.active == "active" || s.active == "reloading"
 
-func executeServiceCommand(serviceName, action string) tea.Cmd {
-	return func() tea.Msg {
-		cmd := exec.Command("systemctl", action, serviceName)
-		err := cmd.Run()
-		
-		if err != nil {
-			return messageMsg{text: fmt.Sprintf("❌ Failed to %s %s: %v", action, serviceName, err)}
+	var actions []serviceAction
+	add := func(allowed bool, label, action string) {
+		if allowed {
+			actions = append(actions, serviceAction{label, action})
+		}
+	}
+	unitType := unitTypeOf(s.name)
+	switch unitType {
+	case "mount", "automount":
+		add(!runningPane && canStart, "Mount", "start")
+		add(canStart && canStop, "Remount", "restart")
+		add(canStop, "Unmount", "stop")
+	case "target", "slice":
+		add(!runningPane && canStart, "Start", "start")
+		add(canStop, "Stop", "stop")
+		add(!runningPane && can("CanIsolate", false), "Isolate", "isolate")
+	default:
+		if runningPane {
+			add(canStop, "Stop", "stop")
+			add(canStart && canStop, "Restart", "restart")
+		} else {
+			add(canStart, "Start", "start")
+			add(canStart && canStop, "Restart", "restart")
+			add(canStop, "Stop", "stop")
+			add(active && canStart && canStop, "Try restart", "try-restart")
+			add(can("CanReload", false) && canStart, "Reload or restart", "reload-or-restart")
 		}
-		
-		return messageMsg{text: fmt.Sprintf("✅ Successfully %sed %s", action, serviceName)}
 	}
+	add(active && can("CanReload", false), "Reload", "reload")
+	add(active && killableTypes[unitType], "Kill…", "kill")
+	add(s.active == "failed", "Reset failed state", "reset-failed")
+
+	switch s.enabled {
+	case "enabled", "enabled-runtime", "alias", "linked", "linked-runtime":
+		actions = append(actions, serviceAction{"Disable", "disable"})
+	case "disabled", "indirect":
+		actions = append(actions, serviceAction{"Enable", "enable"})
+	case "":
+		// Unknown state, let systemctl decide
+		actions = append(actions, serviceAction{"Disable", "disable"}, serviceAction{"Enable", "enable"})
+	}
+	// static, generated, transient and bad units have no install state to change
+
+	add(!runningPane, "Mask", "mask")
+	// Template services need an instance name the timer cannot guess
+	add(unitType == "service" && !strings.Contains(s.name, "@"), "Schedule…", "schedule")
+	return actions
 }
 
-func refreshServices(db *sql.DB) tea.Cmd {
-	return tea.Tick(2*time.Second, func(t time.Time) tea.Msg {
-		return loadServices(db)
-	})
+// actionsDone words the outcome of actions whose past tense isn't "<action>ed".
+var actionsDone = map[string]string{
+	"stop":              "stopped",
+	"enable":            "enabled",
+	"disable":           "disabled",
+	"reset-failed":      "reset the failed state of",
+	"try-restart":       "try-restarted",
+	"reload-or-restart": "reloaded or restarted",
+	"isolate":           "isolated",
 }
 
-func performSearch(searchTerm string, focused int, db *sql.DB) tea.Cmd {
+func executeServiceCommand(manager ServiceManager, serviceName, action string) tea.Cmd {
 	return func() tea.Msg {
-		var services []list.Item
 		var err error
+		switch action {
+		case "start":
+			err = manager.Start(serviceName)
+		case "stop":
+			err = manager.Stop(serviceName)
+		case "restart":
+			err = manager.Restart(serviceName)
+		case "enable":
+			err = manager.Enable(serviceName)
+		case "disable":
+			err = manager.Disable(serviceName)
+		case "mask":
+			err = manager.Mask(serviceName)
+		case "unmask":
+			err = manager.Unmask(serviceName)
+		case "reload":
+			err = manager.Reload(serviceName)
+		case "try-restart":
+			err = manager.TryRestart(serviceName)
+		case "reload-or-restart":
+			err = manager.ReloadOrRestart(serviceName)
+		case "isolate":
+			err = manager.Isolate(serviceName)
+		case "reset-failed":
+			err = manager.ResetFailed(serviceName)
+		default:
+			err = fmt.Errorf("unsupported action %q", action)
+		}
 
-		if focused == 0 {
-			services, err = getAllServices(db)
-		} else {
-			services, err = getRunningServices(db)
+		if err != nil {
+			return messageMsg{text: fmt.Sprintf("❌ Failed to %s %s: %v", action, serviceName, err)}
 		}
 
+		done, ok := actionsDone[action]
+		if !ok {
+			done = action + "ed"
+		}
+		return messageMsg{text: fmt.Sprintf("✅ Successfully %s %s", done, serviceName)}
+	}
+}
+
+func performSearch(searchTerm string, focused int, manager ServiceManager) tea.Cmd {
+	return func() tea.Msg {
+		services, err := manager.ListUnits("service")
 		if err != nil {
 			return messageMsg{text: fmt.Sprintf("Error searching services: %v", err)}
 		}
+		if focused == 1 {
+			services = runningServices(services)
+		}
 
 		// Filter services based on search term
-		var filteredServices []list.Item
+		var filteredServices []service
 		searchLower := strings.ToLower(searchTerm)
 		for _, s := range services {
-			if service, ok := s.(service); ok {
-				if strings.Contains(strings.ToLower(service.name), searchLower) ||
-					strings.Contains(strings.ToLower(service.description), searchLower) {
-					filteredServices = append(filteredServices, s)
-				}
+			if strings.Contains(strings.ToLower(s.name), searchLower) ||
+				strings.Contains(strings.ToLower(s.description), searchLower) {
+				filteredServices = append(filteredServices, s)
 			}
 		}
 
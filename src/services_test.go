@@ -0,0 +1,83 @@
+package main
+
+import "testing"
+
+func TestMergeUnitFileStates(t *testing.T) {
+	units := []service{
+		{name: "getty@tty1.service", active: "active", sub: "running"},
+		{name: "sshd.service", active: "active", sub: "running"},
+		{name: "backup@daily.service", active: "inactive", sub: "dead"},
+	}
+	states := map[string]string{
+		"getty@.service":  "enabled",
+		"sshd.service":    "enabled",
+		"backup@.service": "disabled",
+		"cups.service":    "disabled",
+		"cups.socket":     "enabled",
+	}
+
+	got := make(map[string]service)
+	for _, s := range mergeUnitFileStates(units, states, "service") {
+		got[s.name] = s
+	}
+
+	tests := []struct {
+		name    string
+		enabled string
+	}{
+		{"getty@tty1.service", "enabled"},
+		{"sshd.service", "enabled"},
+		{"backup@daily.service", "disabled"},
+		{"cups.service", "disabled"},
+	}
+	for _, tt := range tests {
+		s, ok := got[tt.name]
+		if !ok {
+			t.Errorf("%s: missing", tt.name)
+			continue
+		}
+		if s.enabled != tt.enabled {
+			t.Errorf("%s: enabled = %q, want %q", tt.name, s.enabled, tt.enabled)
+		}
+	}
+	for _, name := range []string{"getty@.service", "backup@.service", "cups.socket"} {
+		if _, ok := got[name]; ok {
+			t.Errorf("%s: listed, want skipped", name)
+		}
+	}
+}
+
+func TestServiceActionsTemplateInstance(t *testing.T) {
+	states := map[string]string{"getty@.service": "enabled"}
+	s := mergeUnitFileStates([]service{{name: "getty@tty1.service", active: "active", sub: "running"}}, states, "service")[0]
+
+	actions := make(map[string]bool)
+	for _, a := range serviceActions(s, false, nil) {
+		actions[a.action] = true
+	}
+	if actions["enable"] || !actions["disable"] {
+		t.Errorf("instance of an enabled template: actions %v, want disable without enable", actions)
+	}
+}
+
+func TestExecuteServiceCommandMessage(t *testing.T) {
+	tests := []struct {
+		unit   string
+		action string
+		want   string
+	}{
+		{"bluetooth.service", "start", "✅ Successfully started bluetooth.service"},
+		{"cron.service", "stop", "✅ Successfully stopped cron.service"},
+		{"bluetooth.service", "enable", "✅ Successfully enabled bluetooth.service"},
+		{"cron.service", "disable", "✅ Successfully disabled cron.service"},
+		{"nginx.service", "reload-or-restart", "✅ Successfully reloaded or restarted nginx.service"},
+		{"backup.service", "reset-failed", "✅ Successfully reset the failed state of backup.service"},
+	}
+	for _, tt := range tests {
+		msg := executeServiceCommand(newMemoryManager(), tt.unit, tt.action)()
+		got, ok := msg.(messageMsg)
+		if !ok || got.text != tt.want {
+			t.Errorf("%s %s: %#v, want %q", tt.action, tt.unit, msg, tt.want)
+		}
+	}
+}
@@ -0,0 +1,317 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// systemctlManager implements ServiceManager by shelling out to systemctl.
+type systemctlManager struct{}
+
+// systemctl runs systemctl with the given arguments and returns its stdout.
+// On failure the error carries whatever systemctl printed on stderr.
+func (systemctlManager) systemctl(args ...string) ([]byte, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command("systemctl", args...)
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return output, fmt.Errorf("%w: %s", err, msg)
+		}
+		return output, err
+	}
+	return output, nil
+}
+
+// listUnits returns the units matching args, preferring the JSON output of
+// newer systemd versions and falling back to the plain table otherwise.
+func (m systemctlManager) listUnits(args ...string) ([]service, error) {
+	jsonArgs := append([]string{"list-units", "--output=json"}, args...)
+	if output, err := m.systemctl(jsonArgs...); err == nil {
+		if services, err := parseUnitsJSON(output); err == nil {
+			return services, nil
+		}
+	}
+
+	plainArgs := append([]string{"list-units", "--plain", "--no-legend", "--no-pager"}, args...)
+	output, err := m.systemctl(plainArgs...)
+	if err != nil {
+		return nil, err
+	}
+	return parseUnitsPlain(output), nil
+}
+
+// unitJSON is one entry of `systemctl list-units --output=json`.
+type unitJSON struct {
+	Unit        string `json:"unit"`
+	Load        string `json:"load"`
+	Active      string `json:"active"`
+	Sub         string `json:"sub"`
+	Description string `json:"description"`
+}
+
+func parseUnitsJSON(output []byte) ([]service, error) {
+	var units []unitJSON
+	if err := json.Unmarshal(output, &units); err != nil {
+		return nil, err
+	}
+
+	services := make([]service, 0, len(units))
+	for _, u := range units {
+		services = append(services, service{
+			name:        u.Unit,
+			description: u.Description,
+			loaded:      u.Load,
+			active:      u.Active,
+			sub:         u.Sub,
+		})
+	}
+	return services, nil
+}
+
+// parseUnitsPlain parses `systemctl list-units --plain --no-legend`, whose
+// columns are UNIT LOAD ACTIVE SUB DESCRIPTION. Some versions still prefix
+// failed or not-found units with a status bullet, which is skipped.
+func parseUnitsPlain(output []byte) []service {
+	var services []service
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) > 0 && (fields[0] == "●" || fields[0] == "*") {
+			fields = fields[1:]
+		}
+		if len(fields) < 4 {
+			continue
+		}
+		services = append(services, service{
+			name:        fields[0],
+			loaded:      fields[1],
+			active:      fields[2],
+			sub:         fields[3],
+			description: strings.Join(fields[4:], " "),
+		})
+	}
+	return services
+}
+
+// listUnitFiles returns the UnitFileState of every unit file matching args,
+// keyed by unit name.
+func (m systemctlManager) listUnitFiles(args ...string) (map[string]string, error) {
+	jsonArgs := append([]string{"list-unit-files", "--output=json"}, args...)
+	if output, err := m.systemctl(jsonArgs...); err == nil {
+		if states, err := parseUnitFilesJSON(output); err == nil {
+			return states, nil
+		}
+	}
+
+	plainArgs := append([]string{"list-unit-files", "--plain", "--no-legend", "--no-pager"}, args...)
+	output, err := m.systemctl(plainArgs...)
+	if err != nil {
+		return nil, err
+	}
+	return parseUnitFilesPlain(output), nil
+}
+
+// unitFileJSON is one entry of `systemctl list-unit-files --output=json`.
+type unitFileJSON struct {
+	UnitFile string `json:"unit_file"`
+	State    string `json:"state"`
+}
+
+func parseUnitFilesJSON(output []byte) (map[string]string, error) {
+	var files []unitFileJSON
+	if err := json.Unmarshal(output, &files); err != nil {
+		return nil, err
+	}
+
+	states := make(map[string]string, len(files))
+	for _, f := range files {
+		states[f.UnitFile] = f.State
+	}
+	return states, nil
+}
+
+// parseUnitFilesPlain parses `systemctl list-unit-files --plain --no-legend`,
+// whose columns are UNIT FILE, STATE and, on newer versions, VENDOR PRESET.
+func parseUnitFilesPlain(output []byte) map[string]string {
+	states := make(map[string]string)
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		states[fields[0]] = fields[1]
+	}
+	return states
+}
+
+func (m systemctlManager) ListUnits(unitType string) ([]service, error) {
+	var typeArgs []string
+	if unitType != "" {
+		typeArgs = append(typeArgs, "--type="+unitType)
+	}
+
+	units, err := m.listUnits(append(typeArgs, "--all")...)
+	if err != nil {
+		return nil, err
+	}
+
+	// Without unit file states every unit is shown with an unknown state
+	states, err := m.listUnitFiles(typeArgs...)
+	if err != nil {
+		states = nil
+	}
+	return mergeUnitFileStates(units, states, unitType), nil
+}
+
+// ListLoadedUnits implements loadedUnitLister with list-units alone, leaving
+// the unit file states out.
+func (m systemctlManager) ListLoadedUnits() ([]service, error) {
+	return m.listUnits("--all")
+}
+
+func (m systemctlManager) ServiceState(name string) (string, error) {
+	// is-active exits non-zero for anything but "active", so only the output matters
+	output, _ := m.systemctl("is-active", name)
+	state := strings.TrimSpace(string(output))
+	if state == "" {
+		return "", fmt.Errorf("unable to query state of %s", name)
+	}
+	return state, nil
+}
+
+func (m systemctlManager) Start(name string) error {
+	_, err := m.systemctl("start", name)
+	return err
+}
+
+func (m systemctlManager) Stop(name string) error {
+	_, err := m.systemctl("stop", name)
+	return err
+}
+
+func (m systemctlManager) Restart(name string) error {
+	_, err := m.systemctl("restart", name)
+	return err
+}
+
+func (m systemctlManager) Enable(name string) error {
+	_, err := m.systemctl("enable", name)
+	return err
+}
+
+func (m systemctlManager) Disable(name string) error {
+	_, err := m.systemctl("disable", name)
+	return err
+}
+
+func (m systemctlManager) Mask(name string) error {
+	_, err := m.systemctl("mask", name)
+	return err
+}
+
+func (m systemctlManager) Unmask(name string) error {
+	_, err := m.systemctl("unmask", name)
+	return err
+}
+
+func (m systemctlManager) Reload(name string) error {
+	_, err := m.systemctl("reload", name)
+	return err
+}
+
+func (m systemctlManager) TryRestart(name string) error {
+	_, err := m.systemctl("try-restart", name)
+	return err
+}
+
+func (m systemctlManager) ReloadOrRestart(name string) error {
+	_, err := m.systemctl("reload-or-restart", name)
+	return err
+}
+
+func (m systemctlManager) Isolate(name string) error {
+	_, err := m.systemctl("isolate", name)
+	return err
+}
+
+func (m systemctlManager) ResetFailed(name string) error {
+	args := []string{"reset-failed"}
+	if name != "" {
+		args = append(args, name)
+	}
+	_, err := m.systemctl(args...)
+	return err
+}
+
+func (m systemctlManager) Kill(name string, signal syscall.Signal) error {
+	_, err := m.systemctl("kill", "--signal="+strconv.Itoa(int(signal)), name)
+	return err
+}
+
+func (m systemctlManager) DaemonReload() error {
+	_, err := m.systemctl("daemon-reload")
+	return err
+}
+
+func (m systemctlManager) Properties(name string) (map[string]string, error) {
+	output, err := m.systemctl("show", name)
+	if err != nil {
+		return nil, err
+	}
+	return parseShowOutput(output), nil
+}
+
+// showBatchSize bounds the number of units passed to one `systemctl show`.
+const showBatchSize = 200
+
+func (m systemctlManager) UnitsProperties(names []string, props []string) (map[string]map[string]string, error) {
+	result := make(map[string]map[string]string, len(names))
+	for start := 0; start < len(names); start += showBatchSize {
+		end := min(start+showBatchSize, len(names))
+		args := append([]string{"show", "--property=Id," + strings.Join(props, ","), "--"}, names[start:end]...)
+		output, err := m.systemctl(args...)
+		if err != nil {
+			return nil, err
+		}
+		for id, unitProps := range parseShowBlocks(output) {
+			result[id] = unitProps
+		}
+	}
+	return result, nil
+}
+
+// parseShowBlocks parses `systemctl show` of several units, one block per
+// unit separated by blank lines, keyed by each block's Id property.
+func parseShowBlocks(output []byte) map[string]map[string]string {
+	result := make(map[string]map[string]string)
+	for _, block := range strings.Split(string(output), "\n\n") {
+		unitProps := parseShowOutput([]byte(block))
+		if id := unitProps["Id"]; id != "" {
+			result[id] = unitProps
+		}
+	}
+	return result
+}
+
+// parseShowOutput parses the KEY=VALUE lines printed by `systemctl show`.
+// Properties listed once per entry, like Listen or ExecStart, are joined.
+func parseShowOutput(output []byte) map[string]string {
+	props := make(map[string]string)
+	for _, line := range strings.Split(string(output), "\n") {
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		if prev, ok := props[key]; ok {
+			value = prev + "; " + value
+		}
+		props[key] = value
+	}
+	return props
+}
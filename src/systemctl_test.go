@@ -0,0 +1,212 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// The testdata/systemctl files hold systemctl output in the format of the
+// systemd version in their name: 237 (Ubuntu 18.04), 239 (RHEL 8), 245
+// (Ubuntu 20.04) and 252 (Debian 12). The list-unit-files-v252 listings are
+// unedited captures of `systemctl list-unit-files --type=service`.
+func readTestdata(t *testing.T, name string) []byte {
+	t.Helper()
+	output, err := os.ReadFile(filepath.Join("testdata", "systemctl", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return output
+}
+
+func TestParseUnits(t *testing.T) {
+	tests := []struct {
+		file string
+		want []service
+	}{
+		{
+			file: "list-units-v252.json",
+			want: []service{
+				{name: "cron.service", loaded: "loaded", active: "active", sub: "running", description: "Regular background program processing daemon"},
+				{name: "nginx.service", loaded: "loaded", active: "failed", sub: "failed", description: "A high performance web server and a reverse proxy server"},
+				{name: "plymouth-quit.service", loaded: "not-found", active: "inactive", sub: "dead", description: "plymouth-quit.service"},
+				{name: "getty@tty1.service", loaded: "loaded", active: "active", sub: "running", description: "Getty on tty1"},
+			},
+		},
+		{
+			// Failed and not-found units start with a ● bullet
+			file: "list-units-v239.txt",
+			want: []service{
+				{name: "auditd.service", loaded: "loaded", active: "active", sub: "running", description: "Security Auditing Service"},
+				{name: "kdump.service", loaded: "loaded", active: "failed", sub: "failed", description: "Crash recovery kernel arming"},
+				{name: "NetworkManager.service", loaded: "loaded", active: "active", sub: "running", description: "Network Manager"},
+				{name: "ntpd.service", loaded: "not-found", active: "inactive", sub: "dead", description: "ntpd.service"},
+				{name: "sshd.service", loaded: "loaded", active: "active", sub: "running", description: "OpenSSH server daemon"},
+			},
+		},
+		{
+			// Without a UTF-8 locale the bullet is a *
+			file: "list-units-v245.txt",
+			want: []service{
+				{name: "accounts-daemon.service", loaded: "loaded", active: "active", sub: "running", description: "Accounts Service"},
+				{name: "apport.service", loaded: "loaded", active: "active", sub: "exited", description: "LSB: automatic crash report generation"},
+				{name: "snapd.seeded.service", loaded: "loaded", active: "failed", sub: "failed", description: "Wait until snapd is fully seeded"},
+				{name: "systemd-timesyncd.service", loaded: "loaded", active: "active", sub: "running", description: "Network Time Synchronization"},
+				{name: "ufw.service", loaded: "not-found", active: "inactive", sub: "dead", description: "ufw.service"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			output := readTestdata(t, tt.file)
+			var got []service
+			if filepath.Ext(tt.file) == ".json" {
+				var err error
+				if got, err = parseUnitsJSON(output); err != nil {
+					t.Fatal(err)
+				}
+			} else {
+				got = parseUnitsPlain(output)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got  %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseUnitFiles(t *testing.T) {
+	tests := []struct {
+		file  string
+		count int
+		want  map[string]string // a sample of the states
+	}{
+		{
+			// Before the preset column
+			file:  "list-unit-files-v237.txt",
+			count: 5,
+			want: map[string]string{
+				"apache2@.service":         "disabled",
+				"getty@.service":           "enabled",
+				"systemd-journald.service": "static",
+			},
+		},
+		{
+			// With the preset column, "-" for static units
+			file:  "list-unit-files-v252.txt",
+			count: 101,
+			want: map[string]string{
+				"autovt@.service":          "alias",
+				"getty@.service":           "enabled",
+				"hwclock.service":          "masked",
+				"serial-getty@.service":    "disabled",
+				"systemd-journald.service": "static",
+			},
+		},
+		{
+			file:  "list-unit-files-v252.json",
+			count: 101,
+			want: map[string]string{
+				"autovt@.service":          "alias",
+				"getty@.service":           "enabled",
+				"hwclock.service":          "masked",
+				"serial-getty@.service":    "disabled",
+				"systemd-journald.service": "static",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			output := readTestdata(t, tt.file)
+			var got map[string]string
+			if filepath.Ext(tt.file) == ".json" {
+				var err error
+				if got, err = parseUnitFilesJSON(output); err != nil {
+					t.Fatal(err)
+				}
+			} else {
+				got = parseUnitFilesPlain(output)
+			}
+			if len(got) != tt.count {
+				t.Errorf("got %d unit files, want %d", len(got), tt.count)
+			}
+			for name, state := range tt.want {
+				if got[name] != state {
+					t.Errorf("%s: got %q, want %q", name, got[name], state)
+				}
+			}
+		})
+	}
+}
+
+func TestParseUnitFilesPlainMatchesJSON(t *testing.T) {
+	plain := parseUnitFilesPlain(readTestdata(t, "list-unit-files-v252.txt"))
+	json, err := parseUnitFilesJSON(readTestdata(t, "list-unit-files-v252.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(plain, json) {
+		t.Errorf("plain and JSON listings differ:\nplain %v\njson  %v", plain, json)
+	}
+}
+
+func TestParseUnitsJSONInvalid(t *testing.T) {
+	// Versions without --output=json print the plain table instead
+	if _, err := parseUnitsJSON(readTestdata(t, "list-units-v239.txt")); err == nil {
+		t.Error("plain output parsed as JSON")
+	}
+	if _, err := parseUnitFilesJSON(readTestdata(t, "list-unit-files-v237.txt")); err == nil {
+		t.Error("plain output parsed as JSON")
+	}
+}
+
+func TestParseShowBlocks(t *testing.T) {
+	got := parseShowBlocks(readTestdata(t, "show-multi.txt"))
+	want := map[string]map[string]string{
+		"cron.service": {
+			"Id":            "cron.service",
+			"MemoryCurrent": "2424832",
+			"CPUUsageNSec":  "51830000",
+			"TasksCurrent":  "1",
+		},
+		"nginx.service": {
+			"Id":            "nginx.service",
+			"MemoryCurrent": "[not set]",
+			"CPUUsageNSec":  "[not set]",
+			"TasksCurrent":  "5",
+		},
+		"docker.socket": {
+			"Id":           "docker.socket",
+			"Listen":       "/run/docker.sock (Stream); [::]:2375 (Stream)",
+			"TasksCurrent": "[not set]",
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got  %v\nwant %v", got, want)
+	}
+}
+
+func TestParseShowOutput(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   map[string]string
+	}{
+		{"empty", "", map[string]string{}},
+		{"value with =", "Environment=LANG=C PATH=/usr/bin\n", map[string]string{"Environment": "LANG=C PATH=/usr/bin"}},
+		{"empty value", "Description=\nId=a.service\n", map[string]string{"Description": "", "Id": "a.service"}},
+		{
+			"repeated key",
+			"ExecStart={ path=/bin/a ; argv[]=/bin/a }\nExecStart={ path=/bin/b ; argv[]=/bin/b }\n",
+			map[string]string{"ExecStart": "{ path=/bin/a ; argv[]=/bin/a }; { path=/bin/b ; argv[]=/bin/b }"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseShowOutput([]byte(tt.output)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,466 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// untaggedGroup is the section of the grouped view holding units without
+// tags.
+const untaggedGroup = "untagged"
+
+type tagsLoadedMsg struct {
+	tags map[string][]string
+	err  error
+}
+
+type tagsSavedMsg struct {
+	unit string
+	tags []string
+	err  error
+}
+
+type groupsLoadedMsg struct {
+	units []service
+	tags  map[string][]string
+	err   error
+}
+
+// groupSection is one tag of the grouped view with the units carrying it.
+type groupSection struct {
+	tag     string
+	units   []service
+	running int
+	failed  int
+}
+
+// groupsView is the state of the grouped view opened with `G`.
+type groupsView struct {
+	sections  []groupSection
+	collapsed map[string]bool // kept across reloads, keyed by tag
+	cursor    int
+	offset    int
+	loading   bool
+	err       error
+}
+
+// groupRow is one line of the grouped view: a section header when unit is
+// -1, a unit of the section otherwise.
+type groupRow struct {
+	section int
+	unit    int
+}
+
+func loadTags(db *sql.DB) tea.Cmd {
+	return func() tea.Msg {
+		tags, err := loadServiceTags(db)
+		return tagsLoadedMsg{tags: tags, err: err}
+	}
+}
+
+func saveTags(db *sql.DB, unit string, tags []string) tea.Cmd {
+	return func() tea.Msg {
+		return tagsSavedMsg{unit: unit, tags: tags, err: updateServiceTags(db, unit, tags)}
+	}
+}
+
+// parseTags splits the editor input on whitespace and commas, dropping a
+// leading # and duplicates.
+func parseTags(input string) []string {
+	fields := strings.FieldsFunc(input, func(r rune) bool { return unicode.IsSpace(r) || r == ',' })
+	var tags []string
+	for _, f := range fields {
+		tag := strings.ToLower(strings.TrimPrefix(f, "#"))
+		if tag != "" && !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+func formatTags(tags []string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+	return "#" + strings.Join(tags, " #")
+}
+
+// knownTags returns every tag in use, sorted.
+func knownTags(tags map[string][]string) []string {
+	var all []string
+	for _, unitTags := range tags {
+		for _, tag := range unitTags {
+			if !slices.Contains(all, tag) {
+				all = append(all, tag)
+			}
+		}
+	}
+	sort.Strings(all)
+	return all
+}
+
+// applyTags sets the tags of every listed unit and, while a tag filter is
+// set, drops the units without that tag. Favorites are already a short list
+// and are never filtered.
+func (m *model) applyTags() {
+	for _, l := range []*list.Model{&m.allServices, &m.runningServices, &m.failedServices, &m.favoriteServices} {
+		selected := ""
+		if s, ok := l.SelectedItem().(service); ok {
+			selected = s.name
+		}
+		items := make([]list.Item, 0, len(l.Items()))
+		for _, item := range l.Items() {
+			s, ok := item.(service)
+			if !ok {
+				continue
+			}
+			s.tags = formatTags(m.tags[s.name])
+			if m.tagFilter != "" && l != &m.favoriteServices && !slices.Contains(m.tags[s.name], m.tagFilter) {
+				continue
+			}
+			items = append(items, s)
+		}
+		l.SetItems(items)
+		for i, item := range items {
+			if item.(service).name == selected {
+				l.Select(i)
+			}
+		}
+	}
+}
+
+func (m model) openTagEditor(s service) (model, tea.Cmd) {
+	m.selectedService = s
+	m.tagInput = textinput.New()
+	m.tagInput.Placeholder = "web db team-payments"
+	m.tagInput.Width = 50
+	m.tagInput.SetValue(strings.Join(m.tags[s.name], " "))
+	m.tagInput.CursorEnd()
+	m.tagInput.Focus()
+	m.showTagEditor = true
+	return m, textinput.Blink
+}
+
+func (m model) updateTagEditor(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.showTagEditor = false
+		return m, nil
+	case "enter":
+		m.showTagEditor = false
+		return m, saveTags(m.db, m.selectedService.name, parseTags(m.tagInput.Value()))
+	}
+	var cmd tea.Cmd
+	m.tagInput, cmd = m.tagInput.Update(msg)
+	return m, cmd
+}
+
+func (m model) tagEditorView() string {
+	content := fmt.Sprintf("🏷️  Tags: %s\n\n", m.selectedService.name)
+	content += m.tagInput.View() + "\n\n"
+	if known := knownTags(m.tags); len(known) > 0 {
+		content += helpStyle.Render("In use: "+formatTags(known)) + "\n\n"
+	}
+	content += "Separate tags with spaces or commas | Enter: Save | Esc: Cancel"
+	return modalStyle.Render(content)
+}
+
+func (m model) openTagFilter() (model, tea.Cmd) {
+	m.tagInput = textinput.New()
+	m.tagInput.Placeholder = "tag, empty to show every unit"
+	m.tagInput.Width = 40
+	m.tagInput.SetValue(m.tagFilter)
+	m.tagInput.CursorEnd()
+	m.tagInput.Focus()
+	m.showTagFilter = true
+	return m, textinput.Blink
+}
+
+func (m model) updateTagFilter(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.showTagFilter = false
+		return m, nil
+	case "enter":
+		m.showTagFilter = false
+		tag := ""
+		if tags := parseTags(m.tagInput.Value()); len(tags) > 0 {
+			tag = tags[0]
+		}
+		previous := m.tagFilter
+		m.tagFilter = tag
+		m.setPaneTitles()
+		if previous != "" {
+			// Units hidden by the previous filter have to come back
+			return m, tea.Batch(m.loadServices(), loadFailedUnits(m.manager))
+		}
+		m.applyTags()
+		return m, nil
+	case "tab":
+		// Complete from the tags in use
+		for _, tag := range knownTags(m.tags) {
+			if strings.HasPrefix(tag, m.tagInput.Value()) {
+				m.tagInput.SetValue(tag)
+				m.tagInput.CursorEnd()
+				break
+			}
+		}
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.tagInput, cmd = m.tagInput.Update(msg)
+	return m, cmd
+}
+
+func (m model) tagFilterView() string {
+	content := "# Filter by tag\n\n" + m.tagInput.View() + "\n\n"
+	if known := knownTags(m.tags); len(known) > 0 {
+		content += helpStyle.Render(formatTags(known)) + "\n\n"
+	}
+	content += "Tab: Complete | Enter: Apply | Esc: Cancel"
+	return modalStyle.Render(content)
+}
+
+// loadGroups lists the units of every type with their tags, for the
+// grouped view.
+func loadGroups(manager ServiceManager, db *sql.DB) tea.Cmd {
+	return func() tea.Msg {
+		units, err := manager.ListUnits("")
+		if err != nil {
+			return groupsLoadedMsg{err: err}
+		}
+		tags, err := loadServiceTags(db)
+		return groupsLoadedMsg{units: units, tags: tags, err: err}
+	}
+}
+
+// setGroups builds one section per tag, then one for untagged units.
+func (v *groupsView) setGroups(msg groupsLoadedMsg) {
+	v.loading = false
+	v.err = msg.err
+	if msg.err != nil {
+		return
+	}
+
+	byTag := make(map[string]*groupSection)
+	var untagged groupSection
+	untagged.tag = untaggedGroup
+	add := func(section *groupSection, s service) {
+		section.units = append(section.units, s)
+		if isRunning(s) {
+			section.running++
+		}
+		if s.active == "failed" {
+			section.failed++
+		}
+	}
+	for _, s := range msg.units {
+		if len(msg.tags[s.name]) == 0 {
+			add(&untagged, s)
+			continue
+		}
+		for _, tag := range msg.tags[s.name] {
+			if byTag[tag] == nil {
+				byTag[tag] = &groupSection{tag: tag}
+			}
+			add(byTag[tag], s)
+		}
+	}
+
+	v.sections = v.sections[:0]
+	for _, tag := range knownTags(msg.tags) {
+		if section := byTag[tag]; section != nil {
+			v.sections = append(v.sections, *section)
+		}
+	}
+	if len(untagged.units) > 0 {
+		v.sections = append(v.sections, untagged)
+	}
+	v.moveCursor(0, 0)
+}
+
+func (v groupsView) rows() []groupRow {
+	var rows []groupRow
+	for i, section := range v.sections {
+		rows = append(rows, groupRow{section: i, unit: -1})
+		if v.collapsed[section.tag] {
+			continue
+		}
+		for j := range section.units {
+			rows = append(rows, groupRow{section: i, unit: j})
+		}
+	}
+	return rows
+}
+
+func (v *groupsView) moveCursor(delta, height int) {
+	v.cursor += delta
+	if last := len(v.rows()) - 1; v.cursor > last {
+		v.cursor = last
+	}
+	if v.cursor < 0 {
+		v.cursor = 0
+	}
+	if v.cursor < v.offset {
+		v.offset = v.cursor
+	}
+	if height > 0 && v.cursor >= v.offset+height {
+		v.offset = v.cursor - height + 1
+	}
+}
+
+// selected returns the row under the cursor.
+func (v groupsView) selected() (groupRow, bool) {
+	rows := v.rows()
+	if v.cursor >= len(rows) {
+		return groupRow{}, false
+	}
+	return rows[v.cursor], true
+}
+
+func (m model) openGroups() (model, tea.Cmd) {
+	if m.groups.collapsed == nil {
+		// Untagged units are usually most of them, so they start folded
+		m.groups.collapsed = map[string]bool{untaggedGroup: true}
+	}
+	m.groups.loading = true
+	m.showGroups = true
+	return m, loadGroups(m.manager, m.db)
+}
+
+func (m model) groupsHeight() int {
+	return m.height - 5
+}
+
+func (m model) updateGroups(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	v := &m.groups
+	switch msg.String() {
+	case "q", "esc":
+		m.showGroups = false
+		return m, nil
+	case "ctrl+c":
+		return m, tea.Quit
+	case "j", "down":
+		v.moveCursor(1, m.groupsHeight())
+		return m, nil
+	case "k", "up":
+		v.moveCursor(-1, m.groupsHeight())
+		return m, nil
+	case "g":
+		v.moveCursor(-len(v.rows()), m.groupsHeight())
+		return m, nil
+	case "G":
+		v.moveCursor(len(v.rows()), m.groupsHeight())
+		return m, nil
+	case "r":
+		v.loading = true
+		return m, loadGroups(m.manager, m.db)
+	}
+
+	row, ok := v.selected()
+	if !ok {
+		return m, nil
+	}
+	section := v.sections[row.section]
+	if row.unit < 0 {
+		switch msg.String() {
+		case "enter", " ":
+			v.collapsed[section.tag] = !v.collapsed[section.tag]
+		case "left":
+			v.collapsed[section.tag] = true
+		case "right":
+			v.collapsed[section.tag] = false
+		}
+		v.moveCursor(0, m.groupsHeight())
+		return m, nil
+	}
+
+	unit := section.units[row.unit]
+	switch msg.String() {
+	case "left":
+		// Jump to the section header, like the dependency tree
+		v.moveCursor(-row.unit-1, m.groupsHeight())
+	case "i":
+		return m.openInspector(unit.name)
+	case "l":
+		return m.openLogs(unit.name)
+	case "t":
+		m.showGroups = false
+		return m.openTagEditor(unit)
+	case "a", "enter":
+		m.showGroups = false
+		return m.openMenu(unit)
+	}
+	return m, nil
+}
+
+func (m model) groupsView() string {
+	selectedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#FAFAFA")).Background(lipgloss.Color("#7D56F4"))
+	lineStyle := lipgloss.NewStyle().MaxWidth(m.width)
+
+	v := m.groups
+	tags := len(v.sections)
+	if tags > 0 && v.sections[tags-1].tag == untaggedGroup {
+		tags--
+	}
+	status := fmt.Sprintf("%d tags", tags)
+	if tags == 1 {
+		status = "1 tag"
+	}
+	switch {
+	case v.loading:
+		status = "Loading units..."
+	case v.err != nil:
+		status = fmt.Sprintf("❌ Error loading units: %v", v.err)
+	}
+	header := titleStyle.Render("🏷️  Groups") + " " + helpStyle.Render(status)
+
+	var lines []string
+	for i, row := range v.rows() {
+		section := v.sections[row.section]
+		var line string
+		if row.unit < 0 {
+			marker := "▾ "
+			if v.collapsed[section.tag] {
+				marker = "▸ "
+			}
+			name := "#" + section.tag
+			if section.tag == untaggedGroup {
+				name = "(untagged)"
+			}
+			units := "units"
+			if len(section.units) == 1 {
+				units = "unit"
+			}
+			line = fmt.Sprintf("%s%s  %d %s · 🟢 %d running · 🔴 %d failed",
+				marker, unitSectionStyle.Render(name), len(section.units), units, section.running, section.failed)
+		} else {
+			unit := section.units[row.unit]
+			line = "    " + unit.Title() + "  " + helpStyle.Render(unit.description)
+		}
+		if i == v.cursor {
+			line = selectedStyle.Render(line)
+		}
+		lines = append(lines, lineStyle.Render(line))
+	}
+	if height := m.groupsHeight(); height > 0 && v.offset+height < len(lines) {
+		lines = lines[:v.offset+height]
+	}
+	lines = lines[min(v.offset, len(lines)):]
+	if len(v.sections) == 1 && v.sections[0].tag == untaggedGroup {
+		lines = append(lines, "", helpStyle.Render("No tags yet, add some with g on a unit"))
+	}
+
+	help := helpStyle.Render("j/k: Navigate | g/G: Top/Bottom | Enter/Space: Fold section | a/Enter: Actions | i: Inspect | l: Logs | t: Tags | r: Reload | q/Esc: Close")
+	return lipgloss.JoinVertical(lipgloss.Left, header, "", strings.Join(lines, "\n"), "", help)
+}
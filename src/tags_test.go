@@ -0,0 +1,100 @@
+package main
+
+import (
+	"slices"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestParseTags(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{"", nil},
+		{"   ", nil},
+		{"web", []string{"web"}},
+		{"  web   db ", []string{"db", "web"}},
+		{"web,db, cache", []string{"cache", "db", "web"}},
+		{"web\tdb", []string{"db", "web"}},
+		{"#web #db", []string{"db", "web"}},
+		{"web web #web", []string{"web"}},
+		{"Web WEB web", []string{"web"}},
+		{"# , ,, #", nil},
+		{"web,,db", []string{"db", "web"}},
+	}
+	for _, tt := range tests {
+		if got := parseTags(tt.input); !slices.Equal(got, tt.want) {
+			t.Errorf("parseTags(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestApplyTags(t *testing.T) {
+	tags := map[string][]string{
+		"nginx.service": {"web"},
+		"cron.service":  {"ops", "web"},
+		"redis.service": {"db"},
+	}
+	tests := []struct {
+		filter      string
+		wantAll     []string
+		wantRunning []string
+		wantFailed  []string
+	}{
+		{"", nil, nil, nil},
+		{"web", []string{"cron.service", "nginx.service"}, []string{"cron.service", "nginx.service"}, nil},
+		{"db", []string{"redis.service"}, nil, []string{"redis.service"}},
+		{"none", nil, nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run("filter "+tt.filter, func(t *testing.T) {
+			m, _ := newTestModel(t)
+			unfiltered, _, _ := listedNames(m)
+			m.favoriteServices.SetItems(m.allServices.Items()[:2])
+			m.tags, m.tagFilter = tags, tt.filter
+			m.applyTags()
+
+			all, running, failed := listedNames(m)
+			if tt.filter == "" {
+				if !slices.Equal(all, unfiltered) {
+					t.Errorf("listed %v without a filter, want every unit", all)
+				}
+			} else if !slices.Equal(all, tt.wantAll) || !slices.Equal(running, tt.wantRunning) || !slices.Equal(failed, tt.wantFailed) {
+				t.Errorf("listed %v, running %v, failed %v; want %v, %v, %v", all, running, failed, tt.wantAll, tt.wantRunning, tt.wantFailed)
+			}
+			if n := len(m.favoriteServices.Items()); n != 2 {
+				t.Errorf("%d favorites, want both kept whatever the filter", n)
+			}
+			for _, item := range m.allServices.Items() {
+				s := item.(service)
+				if want := formatTags(tags[s.name]); s.tags != want {
+					t.Errorf("%s tags %q, want %q", s.name, s.tags, want)
+				}
+			}
+		})
+	}
+}
+
+func TestGroupsTopBottom(t *testing.T) {
+	m, _ := newTestModel(t)
+	m.height = 40
+	m, cmd := m.openGroups()
+	m = update(t, m, cmd)
+	m.groups.collapsed[untaggedGroup] = false
+	press := func(key string) {
+		t.Helper()
+		next, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+		m = next.(model)
+	}
+
+	press("G")
+	if last := len(m.groups.rows()) - 1; !m.showGroups || m.groups.cursor != last {
+		t.Errorf("G left the cursor at %d, shown %v: want the last row %d", m.groups.cursor, m.showGroups, last)
+	}
+	press("g")
+	if m.groups.cursor != 0 {
+		t.Errorf("g left the cursor at %d, want 0", m.groups.cursor)
+	}
+}
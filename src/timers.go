@@ -0,0 +1,276 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// calendarIterations is how many elapse times the timer wizard previews.
+const calendarIterations = 5
+
+// timerRow is one line of the timers dashboard, like `systemctl list-timers`
+// plus the result of the activated unit's last run.
+type timerRow struct {
+	timer     service
+	activates string
+	next      time.Time // zero when not scheduled
+	last      time.Time // zero when never triggered
+	result    string
+}
+
+type timersLoadedMsg struct {
+	rows []timerRow
+	err  error
+}
+
+// timersView is the state of the timers dashboard opened with `T`.
+type timersView struct {
+	table   table.Model
+	rows    []timerRow
+	loading bool
+	err     error
+}
+
+func loadTimers(manager ServiceManager) tea.Cmd {
+	return func() tea.Msg {
+		timers, err := manager.ListUnits("timer")
+		if err != nil {
+			return timersLoadedMsg{err: err}
+		}
+
+		names := make([]string, 0, len(timers))
+		for _, t := range timers {
+			names = append(names, t.name)
+		}
+		timerProps, err := manager.UnitsProperties(names, []string{"NextElapseUSecRealtime", "LastTriggerUSec", "Unit"})
+		if err != nil {
+			return timersLoadedMsg{err: err}
+		}
+
+		rows := make([]timerRow, 0, len(timers))
+		var activated []string
+		for _, t := range timers {
+			p := timerProps[t.name]
+			row := timerRow{timer: t, activates: p["Unit"]}
+			row.next, _ = parseTimestamp(p["NextElapseUSecRealtime"])
+			row.last, _ = parseTimestamp(p["LastTriggerUSec"])
+			rows = append(rows, row)
+			if row.activates != "" {
+				activated = append(activated, row.activates)
+			}
+		}
+
+		// The last result is a property of the activated unit, not the timer
+		if results, err := manager.UnitsProperties(activated, []string{"Result"}); err == nil {
+			for i, row := range rows {
+				rows[i].result = results[row.activates]["Result"]
+			}
+		}
+
+		// Soonest first, unscheduled timers last, like list-timers
+		sort.SliceStable(rows, func(i, j int) bool {
+			if rows[i].next.IsZero() != rows[j].next.IsZero() {
+				return !rows[i].next.IsZero()
+			}
+			return rows[i].next.Before(rows[j].next)
+		})
+		return timersLoadedMsg{rows: rows}
+	}
+}
+
+func newTimersTable(width, height int) table.Model {
+	columns := []table.Column{
+		{Title: "NEXT", Width: 17},
+		{Title: "LEFT", Width: 11},
+		{Title: "LAST", Width: 17},
+		{Title: "PASSED", Width: 14},
+		{Title: "UNIT", Width: 26},
+		{Title: "ACTIVATES", Width: 26},
+		{Title: "RESULT", Width: 10},
+		{Title: "STATE", Width: 10},
+	}
+	return newStyledTable(columns, width, height)
+}
+
+// newStyledTable builds a focused table with the dashboard header and
+// selection colors.
+func newStyledTable(columns []table.Column, width, height int) table.Model {
+	t := table.New(
+		table.WithColumns(columns),
+		table.WithFocused(true),
+		table.WithWidth(width),
+		table.WithHeight(height),
+	)
+
+	styles := table.DefaultStyles()
+	styles.Header = styles.Header.
+		BorderStyle(lipgloss.NormalBorder()).
+		BorderForeground(lipgloss.Color("#626262")).
+		BorderBottom(true).
+		Bold(true)
+	styles.Selected = styles.Selected.
+		Foreground(lipgloss.Color("#FAFAFA")).
+		Background(lipgloss.Color("#7D56F4")).
+		Bold(false)
+	t.SetStyles(styles)
+	return t
+}
+
+// render refreshes the table rows from the loaded timers.
+func (v *timersView) render() {
+	now := time.Now()
+	rows := make([]table.Row, 0, len(v.rows))
+	for _, r := range v.rows {
+		next, left, last, passed := "n/a", "n/a", "n/a", "n/a"
+		if !r.next.IsZero() {
+			next, left = r.next.Format("2006-01-02 15:04"), formatSince(r.next, now)
+		}
+		if !r.last.IsZero() {
+			last, passed = r.last.Format("2006-01-02 15:04"), formatSince(r.last, now)+" ago"
+		}
+		result := r.result
+		if result == "" {
+			result = "-"
+		}
+		rows = append(rows, table.Row{next, left, last, passed, r.timer.name, r.activates, result, r.timer.enabled})
+	}
+	v.table.SetRows(rows)
+}
+
+func (v timersView) selected() (timerRow, bool) {
+	cursor := v.table.Cursor()
+	if cursor < 0 || cursor >= len(v.rows) {
+		return timerRow{}, false
+	}
+	return v.rows[cursor], true
+}
+
+// openTimers shows the timers dashboard.
+func (m model) openTimers() (model, tea.Cmd) {
+	m.timers = timersView{
+		table:   newTimersTable(m.width, m.height-6),
+		loading: true,
+	}
+	m.showTimers = true
+	return m, loadTimers(m.manager)
+}
+
+func (m model) updateTimers(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "q", "esc", "T":
+		m.showTimers = false
+		return m, nil
+	case "ctrl+c":
+		return m, tea.Quit
+	case "r":
+		m.timers.loading = true
+		return m, loadTimers(m.manager)
+	}
+
+	row, ok := m.timers.selected()
+	if ok {
+		switch msg.String() {
+		case "s", "enter":
+			if row.activates == "" {
+				return m, nil
+			}
+			return m.perform(pendingAction{
+				action: "start",
+				label:  "Start " + row.activates,
+				units:  []string{row.activates},
+				run:    tea.Sequence(executeServiceCommand(m.manager, row.activates, "start"), loadTimers(m.manager)),
+			})
+		case "e":
+			action := "enable"
+			switch row.timer.enabled {
+			case "enabled", "enabled-runtime", "alias", "linked", "linked-runtime":
+				action = "disable"
+			case "static", "generated", "transient", "masked", "masked-runtime":
+				m.message = fmt.Sprintf("❌ %s is %s and cannot be enabled or disabled", row.timer.name, row.timer.enabled)
+				return m, nil
+			}
+			return m.perform(pendingAction{
+				action: action,
+				label:  strings.ToUpper(action[:1]) + action[1:] + " " + row.timer.name,
+				units:  []string{row.timer.name},
+				run:    tea.Sequence(executeServiceCommand(m.manager, row.timer.name, action), loadTimers(m.manager)),
+			})
+		case "l":
+			if row.activates != "" {
+				return m.openLogs(row.activates)
+			}
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	m.timers.table, cmd = m.timers.table.Update(msg)
+	return m, cmd
+}
+
+func (m model) timersView() string {
+	status := fmt.Sprintf("%d timers", len(m.timers.rows))
+	switch {
+	case m.timers.loading:
+		status = "Loading timers..."
+	case m.timers.err != nil:
+		status = fmt.Sprintf("❌ Error loading timers: %v", m.timers.err)
+	}
+	header := titleStyle.Render("⏰ Timers") + " " + helpStyle.Render(status)
+
+	help := helpStyle.Render("j/k: Navigate | s/Enter: Run service now | e: Enable/Disable timer | l: Service logs | r: Reload | q/Esc: Close")
+	view := lipgloss.JoinVertical(lipgloss.Left, header, "", m.timers.table.View(), "", help)
+	if m.message != "" {
+		view += "\n" + messageStyle.Render(m.message)
+	}
+	return view
+}
+
+// newTimerWizard builds the wizard creating a timer of the same name that
+// activates service.
+func newTimerWizard(service string) wizard {
+	timer := strings.TrimSuffix(service, ".service") + ".timer"
+	w := newWizard("⏰ Schedule "+service, []wizardField{
+		{label: "OnCalendar", placeholder: "daily, Mon..Fri 09:00, *-*-* 04:30:00", required: true},
+		{label: "Persistent (catch up on missed runs)", choices: []string{"true", "false"}},
+		{label: "RandomizedDelaySec", placeholder: "e.g. 15min, none when empty"},
+	}, func(v []string) (string, string, error) {
+		var b strings.Builder
+		fmt.Fprintf(&b, "[Unit]\nDescription=Run %s on schedule\n\n", service)
+		fmt.Fprintf(&b, "[Timer]\nOnCalendar=%s\nPersistent=%s\n", v[0], v[1])
+		if v[2] != "" {
+			fmt.Fprintf(&b, "RandomizedDelaySec=%s\n", v[2])
+		}
+		b.WriteString("\n[Install]\nWantedBy=timers.target\n")
+		return timer, b.String(), nil
+	})
+	w.check = func(v []string) tea.Cmd {
+		return checkCalendar(v[0])
+	}
+	w.enable, w.start = true, true
+	return w
+}
+
+// checkCalendar validates a calendar expression and lists its next elapse
+// times with `systemd-analyze calendar`.
+func checkCalendar(expr string) tea.Cmd {
+	return func() tea.Msg {
+		var output bytes.Buffer
+		cmd := exec.Command("systemd-analyze", "calendar", "--iterations="+strconv.Itoa(calendarIterations), expr)
+		cmd.Stdout, cmd.Stderr = &output, &output
+		err := cmd.Run()
+		if err != nil {
+			err = fmt.Errorf("invalid calendar expression %q", expr)
+		}
+		return wizardCheckedMsg{output: strings.TrimRight(output.String(), "\n"), err: err}
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type transientStartedMsg struct {
+	unit   string
+	output string
+	err    error
+}
+
+// newTransientWizard builds the form launching a command as a transient unit
+// with `systemd-run`.
+func newTransientWizard(manager ServiceManager) wizard {
+	w := newWizard("🚀 Run transient unit", []wizardField{
+		{label: "Unit name", placeholder: "myjob (.service or .scope is added)", required: true},
+		{label: "Command", placeholder: "/usr/bin/rsync -a /srv/data /backup (split on spaces)", required: true},
+		{label: "Run as", choices: []string{"service", "scope"}},
+		{label: "MemoryMax", placeholder: "e.g. 512M, no limit when empty"},
+		{label: "CPUQuota", placeholder: "e.g. 50%, no limit when empty"},
+		{label: "OnCalendar", placeholder: "e.g. hourly, runs right away when empty"},
+	}, func(v []string) (string, string, error) {
+		unit, args, err := transientArgs(v)
+		if err != nil {
+			return "", "", err
+		}
+		return unit, "systemd-run " + shellJoin(args), nil
+	})
+	w.launch = func(v []string) tea.Cmd {
+		unit, args, err := transientArgs(v)
+		if err != nil {
+			return func() tea.Msg { return transientStartedMsg{unit: unit, err: err} }
+		}
+		return runTransient(manager, unit, args, v[2] == "scope")
+	}
+	return w
+}
+
+// transientArgs turns the answers of the transient wizard into the unit name
+// and the arguments of `systemd-run`.
+func transientArgs(v []string) (string, []string, error) {
+	mode, memoryMax, cpuQuota, onCalendar := v[2], v[3], v[4], v[5]
+	unit, err := unitName(v[0], mode)
+	if err != nil {
+		return "", nil, err
+	}
+	if mode == "scope" && onCalendar != "" {
+		return "", nil, fmt.Errorf("scopes run right away and cannot be scheduled with OnCalendar")
+	}
+
+	args := []string{"--unit=" + unit}
+	if memoryMax != "" {
+		args = append(args, "--property=MemoryMax="+memoryMax)
+	}
+	if cpuQuota != "" {
+		args = append(args, "--property=CPUQuota="+cpuQuota)
+	}
+	if mode == "scope" {
+		args = append(args, "--scope")
+	}
+	if onCalendar != "" {
+		args = append(args, "--on-calendar="+onCalendar)
+	}
+	args = append(args, "--")
+	args = append(args, strings.Fields(v[1])...)
+	return unit, args, nil
+}
+
+// shellJoin quotes args for display like a shell would need them. Only
+// arguments made of characters no shell treats specially are left bare.
+func shellJoin(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		if arg == "" || strings.IndexFunc(arg, needsShellQuote) >= 0 {
+			arg = "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+		}
+		quoted[i] = arg
+	}
+	return strings.Join(quoted, " ")
+}
+
+func needsShellQuote(r rune) bool {
+	switch {
+	case 'a' <= r && r <= 'z', 'A' <= r && r <= 'Z', '0' <= r && r <= '9':
+		return false
+	}
+	return !strings.ContainsRune("@%+=:,./_-", r)
+}
+
+// runTransient starts the unit. A scope runs the command as a child of
+// systemd-run in the foreground, so it is started without waiting for it.
+func runTransient(manager ServiceManager, unit string, args []string, scope bool) tea.Cmd {
+	return func() tea.Msg {
+		output, err := auditAction(manager, unit, "run-transient", func() (string, error) {
+			cmd := exec.Command("systemd-run", args...)
+			if scope {
+				if err := cmd.Start(); err != nil {
+					return "", err
+				}
+				go cmd.Wait()
+				return "", nil
+			}
+			output, err := cmd.CombinedOutput()
+			return strings.TrimSpace(string(output)), err
+		})
+		return transientStartedMsg{unit: unit, output: output, err: err}
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTransientArgs(t *testing.T) {
+	tests := []struct {
+		name     string
+		answers  []string // unit name, command, run as, MemoryMax, CPUQuota, OnCalendar
+		wantUnit string
+		wantArgs []string
+		wantErr  bool
+	}{
+		{
+			name:     "service without limits",
+			answers:  []string{"backup", "/usr/bin/rsync -a /srv/data /backup", "service", "", "", ""},
+			wantUnit: "backup.service",
+			wantArgs: []string{"--unit=backup.service", "--", "/usr/bin/rsync", "-a", "/srv/data", "/backup"},
+		},
+		{
+			name:     "every property",
+			answers:  []string{"backup.service", "/usr/bin/rsync -a /srv/data /backup", "service", "512M", "50%", "Mon *-*-* 02:00"},
+			wantUnit: "backup.service",
+			wantArgs: []string{"--unit=backup.service", "--property=MemoryMax=512M", "--property=CPUQuota=50%", "--on-calendar=Mon *-*-* 02:00", "--", "/usr/bin/rsync", "-a", "/srv/data", "/backup"},
+		},
+		{
+			name:     "empty memory limit",
+			answers:  []string{"job", "sleep 60", "service", "", "25%", ""},
+			wantUnit: "job.service",
+			wantArgs: []string{"--unit=job.service", "--property=CPUQuota=25%", "--", "sleep", "60"},
+		},
+		{
+			// The command is split on whitespace, quotes stay in the arguments
+			name:     "quotes and repeated spaces",
+			answers:  []string{"greet", `echo  "hello   world" it's`, "service", "", "", ""},
+			wantUnit: "greet.service",
+			wantArgs: []string{"--unit=greet.service", "--", "echo", `"hello`, `world"`, "it's"},
+		},
+		{
+			name:     "scope",
+			answers:  []string{"build", "make -j4", "scope", "2G", "", ""},
+			wantUnit: "build.scope",
+			wantArgs: []string{"--unit=build.scope", "--property=MemoryMax=2G", "--scope", "--", "make", "-j4"},
+		},
+		{name: "scheduled scope", answers: []string{"build", "make", "scope", "", "", "hourly"}, wantErr: true},
+		{name: "name with a space", answers: []string{"my job", "true", "service", "", "", ""}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			unit, args, err := transientArgs(tt.answers)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error %v, want error %v", err, tt.wantErr)
+			}
+			if unit != tt.wantUnit || !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("got %q %q, want %q %q", unit, args, tt.wantUnit, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestShellJoin(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"--unit=backup.service", "--", "/usr/bin/rsync", "-a"}, "--unit=backup.service -- /usr/bin/rsync -a"},
+		{[]string{"--property=CPUQuota=50%", "user@host:/srv"}, "--property=CPUQuota=50% user@host:/srv"},
+		{[]string{"--on-calendar=Mon *-*-* 02:00"}, "'--on-calendar=Mon *-*-* 02:00'"},
+		{[]string{"echo", "it's"}, `echo 'it'\''s'`},
+		{[]string{"echo", `"hi"`, "$HOME"}, `echo '"hi"' '$HOME'`},
+		{[]string{"echo", ""}, "echo ''"},
+	}
+	for _, tt := range tests {
+		if got := shellJoin(tt.args); got != tt.want {
+			t.Errorf("shellJoin(%q) = %s, want %s", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestShellJoinRoundTrip(t *testing.T) {
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("no sh")
+	}
+	args := []string{"", "a b", "it's", `"quoted"`, "$HOME", `back\slash`, "*", "tab\there", "new\nline",
+		"[abc]", "~root", "#comment", "!", "{a,b}", "a;b", "a&&b", "`id`", "héllo", "--property=MemoryMax=512M"}
+	output, err := exec.Command(sh, "-c", `printf '%s\0' `+shellJoin(args)).Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := strings.Split(strings.TrimSuffix(string(output), "\x00"), "\x00")
+	if !reflect.DeepEqual(got, args) {
+		t.Errorf("the shell read %q\nfrom %s\nwant %q", got, shellJoin(args), args)
+	}
+}
@@ -0,0 +1,305 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// vendorUnitDirs are where packages install unit files, which files in /etc
+// and /run override.
+var vendorUnitDirs = []string{"/usr/lib/systemd/system", "/lib/systemd/system"}
+
+var (
+	unitSectionStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#7D56F4")).Bold(true)
+	unitKeyStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("#04B575"))
+	unitPathStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("#FFD700")).Bold(true)
+	diffAddStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("#04B575"))
+	diffRemoveStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("#FF5F87"))
+)
+
+// unitFile is one file making up a unit's configuration.
+type unitFile struct {
+	path    string
+	content string
+	err     error
+}
+
+type unitFilesLoadedMsg struct {
+	unit   string
+	files  []unitFile // the fragment first, then the drop-ins in order
+	vendor unitFile   // the shipped file the fragment overrides, if any
+	err    error
+}
+
+// unitFileView is the state of the unit file viewer opened with `c`.
+type unitFileView struct {
+	unit     string
+	files    []unitFile
+	vendor   unitFile
+	diff     bool
+	loading  bool
+	err      error
+	viewport viewport.Model
+}
+
+func loadUnitFiles(manager ServiceManager, unit string) tea.Cmd {
+	return func() tea.Msg {
+		props, err := manager.UnitsProperties([]string{unit}, []string{"FragmentPath", "DropInPaths"})
+		if err != nil {
+			return unitFilesLoadedMsg{unit: unit, err: err}
+		}
+
+		var paths []string
+		if fragment := props[unit]["FragmentPath"]; fragment != "" {
+			paths = append(paths, fragment)
+		}
+		paths = append(paths, strings.Fields(props[unit]["DropInPaths"])...)
+		if len(paths) == 0 {
+			return unitFilesLoadedMsg{unit: unit, err: fmt.Errorf("%s has no unit file", unit)}
+		}
+
+		msg := unitFilesLoadedMsg{unit: unit}
+		for _, path := range paths {
+			content, err := os.ReadFile(path)
+			msg.files = append(msg.files, unitFile{path: path, content: string(content), err: err})
+		}
+		if fragment := props[unit]["FragmentPath"]; fragment != "" {
+			msg.vendor = vendorUnitFile(fragment)
+		}
+		return msg
+	}
+}
+
+// vendorUnitFile finds the shipped unit file that fragment overrides. It is
+// empty when fragment is itself the vendor file or overrides nothing.
+func vendorUnitFile(fragment string) unitFile {
+	for _, dir := range vendorUnitDirs {
+		if filepath.Dir(fragment) == dir {
+			return unitFile{}
+		}
+	}
+	for _, dir := range vendorUnitDirs {
+		path := filepath.Join(dir, filepath.Base(fragment))
+		if content, err := os.ReadFile(path); err == nil {
+			return unitFile{path: path, content: string(content)}
+		}
+	}
+	return unitFile{}
+}
+
+// highlightUnitLine colors the sections, keys and comments of a unit file.
+func highlightUnitLine(line string) string {
+	trimmed := strings.TrimSpace(line)
+	switch {
+	case strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, ";"):
+		return helpStyle.Render(line)
+	case strings.HasPrefix(trimmed, "[") && strings.HasSuffix(trimmed, "]"):
+		return unitSectionStyle.Render(line)
+	}
+	if key, value, ok := strings.Cut(line, "="); ok {
+		return unitKeyStyle.Render(key) + "=" + value
+	}
+	return line
+}
+
+// diffOp is one line of a line diff: ' ' kept, '-' removed or '+' added.
+type diffOp struct {
+	op   byte
+	text string
+}
+
+// diffLines computes a line diff of a and b from their longest common
+// subsequence. Unit files are small enough for the quadratic table.
+func diffLines(a, b []string) []diffOp {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var ops []diffOp
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, diffOp{' ', a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, diffOp{'-', a[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		ops = append(ops, diffOp{'-', a[i]})
+	}
+	for ; j < len(b); j++ {
+		ops = append(ops, diffOp{'+', b[j]})
+	}
+	return ops
+}
+
+func splitLines(content string) []string {
+	if content == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+}
+
+// catLines renders the files like `systemctl cat`.
+func (v unitFileView) catLines() []string {
+	var lines []string
+	for i, f := range v.files {
+		if i > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, unitPathStyle.Render("# "+f.path))
+		if f.err != nil {
+			lines = append(lines, diffRemoveStyle.Render(fmt.Sprintf("# %v", f.err)))
+			continue
+		}
+		for _, line := range splitLines(f.content) {
+			lines = append(lines, highlightUnitLine(line))
+		}
+	}
+	return lines
+}
+
+// deltaLines renders how the unit differs from what was shipped, in the
+// categories of `systemd-delta`.
+func (v unitFileView) deltaLines() []string {
+	var lines []string
+	if len(v.files) > 0 && v.vendor.path != "" && v.files[0].err == nil {
+		lines = append(lines, unitPathStyle.Render(fmt.Sprintf("[OVERRIDDEN] %s → %s", v.files[0].path, v.vendor.path)), "")
+		for _, d := range diffLines(splitLines(v.vendor.content), splitLines(v.files[0].content)) {
+			switch d.op {
+			case '-':
+				lines = append(lines, diffRemoveStyle.Render("-"+d.text))
+			case '+':
+				lines = append(lines, diffAddStyle.Render("+"+d.text))
+			default:
+				lines = append(lines, " "+d.text)
+			}
+		}
+	}
+
+	for i, f := range v.files {
+		if i == 0 {
+			continue
+		}
+		if len(lines) > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, unitPathStyle.Render(fmt.Sprintf("[EXTENDED] %s → %s", v.files[0].path, f.path)), "")
+		if f.err != nil {
+			lines = append(lines, diffRemoveStyle.Render(fmt.Sprintf("# %v", f.err)))
+			continue
+		}
+		for _, line := range splitLines(f.content) {
+			lines = append(lines, diffAddStyle.Render("+"+line))
+		}
+	}
+
+	if len(lines) == 0 {
+		lines = append(lines, helpStyle.Render("No overrides, the unit is used as shipped"))
+	}
+	return lines
+}
+
+func (v *unitFileView) render() {
+	var lines []string
+	switch {
+	case v.loading:
+		lines = []string{"Loading unit files..."}
+	case v.err != nil:
+		lines = []string{fmt.Sprintf("❌ Error loading unit files: %v", v.err)}
+	case v.diff:
+		lines = v.deltaLines()
+	default:
+		lines = v.catLines()
+	}
+
+	lineStyle := lipgloss.NewStyle().MaxWidth(v.viewport.Width)
+	for i, line := range lines {
+		lines[i] = lineStyle.Render(line)
+	}
+	v.viewport.SetContent(strings.Join(lines, "\n"))
+}
+
+// openUnitFiles shows the unit file viewer for unit.
+func (m model) openUnitFiles(unit string) (model, tea.Cmd) {
+	m.unitFiles = unitFileView{
+		unit:     unit,
+		loading:  true,
+		viewport: viewport.New(m.width, m.height-4),
+	}
+	m.unitFiles.render()
+	m.showUnitFiles = true
+	return m, loadUnitFiles(m.manager, unit)
+}
+
+func (m model) updateUnitFiles(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "q", "esc", "c":
+		m.showUnitFiles = false
+		return m, nil
+	case "ctrl+c":
+		return m, tea.Quit
+	case "e":
+		m.showUnitFiles = false
+		return m.openOverrideEditor(m.unitFiles.unit)
+	case "d":
+		m.unitFiles.diff = !m.unitFiles.diff
+		m.unitFiles.render()
+		m.unitFiles.viewport.GotoTop()
+		return m, nil
+	case "r":
+		m.unitFiles.loading = true
+		m.unitFiles.render()
+		return m, loadUnitFiles(m.manager, m.unitFiles.unit)
+	case "g":
+		m.unitFiles.viewport.GotoTop()
+		return m, nil
+	case "G":
+		m.unitFiles.viewport.GotoBottom()
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.unitFiles.viewport, cmd = m.unitFiles.viewport.Update(msg)
+	return m, cmd
+}
+
+func (m model) unitFilesView() string {
+	mode := "cat"
+	if m.unitFiles.diff {
+		mode = "delta"
+	}
+	status := fmt.Sprintf("%s | %d files", mode, len(m.unitFiles.files))
+	if m.unitFiles.vendor.path != "" {
+		status += " | overrides " + m.unitFiles.vendor.path
+	}
+	header := titleStyle.Render(fmt.Sprintf("📄 Unit file: %s", m.unitFiles.unit)) + " " + helpStyle.Render(status)
+
+	help := helpStyle.Render("j/k: Scroll | g/G: Top/Bottom | d: Toggle vendor diff | e: Edit override | r: Reload | q/Esc: Close")
+	return lipgloss.JoinVertical(lipgloss.Left, header, "", m.unitFiles.viewport.View(), help)
+}
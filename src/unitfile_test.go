@@ -0,0 +1,88 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want []diffOp
+	}{
+		{
+			name: "identical",
+			a:    "[Service]\nExecStart=/usr/sbin/nginx\n",
+			b:    "[Service]\nExecStart=/usr/sbin/nginx\n",
+			want: []diffOp{{' ', "[Service]"}, {' ', "ExecStart=/usr/sbin/nginx"}},
+		},
+		{
+			name: "only additions",
+			a:    "[Service]\nExecStart=/usr/sbin/nginx\n",
+			b:    "[Service]\nUser=www-data\nExecStart=/usr/sbin/nginx\nRestart=always\n",
+			want: []diffOp{{' ', "[Service]"}, {'+', "User=www-data"}, {' ', "ExecStart=/usr/sbin/nginx"}, {'+', "Restart=always"}},
+		},
+		{
+			name: "only removals",
+			a:    "[Unit]\nAfter=network.target\n[Service]\nExecStart=/usr/sbin/nginx\n",
+			b:    "[Service]\nExecStart=/usr/sbin/nginx\n",
+			want: []diffOp{{'-', "[Unit]"}, {'-', "After=network.target"}, {' ', "[Service]"}, {' ', "ExecStart=/usr/sbin/nginx"}},
+		},
+		{
+			name: "changed line",
+			a:    "[Service]\nRestart=no\nUser=root\n",
+			b:    "[Service]\nRestart=always\nUser=root\n",
+			want: []diffOp{{' ', "[Service]"}, {'-', "Restart=no"}, {'+', "Restart=always"}, {' ', "User=root"}},
+		},
+		{
+			name: "empty vendor file",
+			a:    "",
+			b:    "[Service]\nUser=root\n",
+			want: []diffOp{{'+', "[Service]"}, {'+', "User=root"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := diffLines(splitLines(tt.a), splitLines(tt.b)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got  %q\nwant %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVendorUnitFile(t *testing.T) {
+	usrLib, lib := t.TempDir(), t.TempDir()
+	saved := vendorUnitDirs
+	vendorUnitDirs = []string{usrLib, lib}
+	t.Cleanup(func() { vendorUnitDirs = saved })
+
+	write := func(dir, name, content string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(usrLib, "nginx.service", "[Service]\nExecStart=/usr/sbin/nginx\n")
+	write(lib, "nginx.service", "[Service]\nExecStart=/old/nginx\n")
+	write(lib, "cron.service", "[Service]\nExecStart=/usr/sbin/cron\n")
+
+	tests := []struct {
+		name     string
+		fragment string
+		want     unitFile
+	}{
+		{"first directory wins", "/etc/systemd/system/nginx.service", unitFile{path: filepath.Join(usrLib, "nginx.service"), content: "[Service]\nExecStart=/usr/sbin/nginx\n"}},
+		{"later directory", "/etc/systemd/system/cron.service", unitFile{path: filepath.Join(lib, "cron.service"), content: "[Service]\nExecStart=/usr/sbin/cron\n"}},
+		{"fragment is the vendor file", filepath.Join(lib, "cron.service"), unitFile{}},
+		{"not in the search path", "/etc/systemd/system/backup.service", unitFile{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := vendorUnitFile(tt.fragment); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
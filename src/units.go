@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// unitTypeInfo describes one tab of the unit type switcher.
+type unitTypeInfo struct {
+	name   string // unit suffix, e.g. "timer"
+	plural string // used in pane titles
+	// properties are fetched for every listed unit and passed to details
+	properties []string
+	details    func(props map[string]string) string
+}
+
+// unitTypes are the unit types the switcher cycles through with [ and ].
+var unitTypes = []unitTypeInfo{
+	{name: "service", plural: "Services"},
+	{
+		name:       "timer",
+		plural:     "Timers",
+		properties: []string{"NextElapseUSecRealtime", "LastTriggerUSec", "Unit"},
+		details: func(p map[string]string) string {
+			return fmt.Sprintf("⏭ %s · ⏮ %s · → %s",
+				formatTimestamp(p["NextElapseUSecRealtime"]), formatTimestamp(p["LastTriggerUSec"]), p["Unit"])
+		},
+	},
+	{
+		name:       "socket",
+		plural:     "Sockets",
+		properties: []string{"Listen", "NConnections"},
+		details: func(p map[string]string) string {
+			return fmt.Sprintf("👂 %s · %s connections", p["Listen"], p["NConnections"])
+		},
+	},
+	{
+		name:       "mount",
+		plural:     "Mounts",
+		properties: []string{"What", "Where"},
+		details: func(p map[string]string) string {
+			return fmt.Sprintf("%s → %s", p["What"], p["Where"])
+		},
+	},
+	{
+		name:       "automount",
+		plural:     "Automounts",
+		properties: []string{"Where"},
+		details: func(p map[string]string) string {
+			return "📂 " + p["Where"]
+		},
+	},
+	{
+		name:       "path",
+		plural:     "Paths",
+		properties: []string{"Paths", "Unit"},
+		details: func(p map[string]string) string {
+			return fmt.Sprintf("👀 %s · → %s", p["Paths"], p["Unit"])
+		},
+	},
+	{name: "target", plural: "Targets"},
+	{
+		name:       "slice",
+		plural:     "Slices",
+		properties: []string{"MemoryCurrent", "TasksCurrent"},
+		details: func(p map[string]string) string {
+			return fmt.Sprintf("🧠 %s · %s tasks", formatPropertyValue("MemoryCurrent", p["MemoryCurrent"]), p["TasksCurrent"])
+		},
+	},
+}
+
+// unitTypeOf returns the type suffix of a unit name, e.g. "timer".
+func unitTypeOf(name string) string {
+	return name[strings.LastIndex(name, ".")+1:]
+}
+
+// parseTimestamp reads a timestamp property, which systemctl has already
+// formatted in local time but D-Bus reports in microseconds since the epoch.
+// Unset timestamps report false.
+func parseTimestamp(value string) (time.Time, bool) {
+	if usec, err := strconv.ParseUint(value, 10, 64); err == nil {
+		if usec == 0 || value == unsetUint64 {
+			return time.Time{}, false
+		}
+		return time.UnixMicro(int64(usec)), true
+	}
+	t, err := time.ParseInLocation("Mon 2006-01-02 15:04:05 MST", value, time.Local)
+	return t, err == nil
+}
+
+func formatTimestamp(value string) string {
+	t, ok := parseTimestamp(value)
+	if !ok {
+		return "n/a"
+	}
+	return t.Format("Mon 2006-01-02 15:04")
+}
+
+// formatSince renders how far t is from now the way list-timers does,
+// e.g. "3h 12min" or "2 days".
+func formatSince(t time.Time, now time.Time) string {
+	d := t.Sub(now)
+	if d < 0 {
+		d = -d
+	}
+	switch {
+	case d >= 48*time.Hour:
+		return fmt.Sprintf("%d days", int(d.Hours()/24))
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh %dmin", int(d.Hours()), int(d.Minutes())%60)
+	case d >= time.Minute:
+		return fmt.Sprintf("%dmin %ds", int(d.Minutes()), int(d.Seconds())%60)
+	}
+	return fmt.Sprintf("%ds", int(d.Seconds()))
+}
+
+// addUnitDetails fills the type-specific column of each unit from the
+// properties its type asks for. Failing to fetch them is not fatal, the
+// units are simply shown without details.
+func addUnitDetails(manager ServiceManager, info unitTypeInfo, units []service) {
+	if len(info.properties) == 0 || len(units) == 0 {
+		return
+	}
+
+	names := make([]string, 0, len(units))
+	for _, s := range units {
+		names = append(names, s.name)
+	}
+	props, err := manager.UnitsProperties(names, info.properties)
+	if err != nil {
+		return
+	}
+	for i, s := range units {
+		if p, ok := props[s.name]; ok {
+			units[i].details = info.details(p)
+		}
+	}
+}
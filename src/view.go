@@ -2,10 +2,13 @@ package main
 
 import (
 	"fmt"
+	"strings"
+
 	"github.com/charmbracelet/lipgloss"
 )
 
-var ( titleStyle = lipgloss.NewStyle(). Foreground(lipgloss.Color("#FAFAFA")). Background(lipgloss.Color("#7D56F4")). Padding(0, 1). Bold(true)
+var (
+	titleStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#FAFAFA")).Background(lipgloss.Color("#7D56F4")).Padding(0, 1).Bold(true)
 
 	focusedStyle = lipgloss.NewStyle().
 			Border(lipgloss.RoundedBorder()).
@@ -42,20 +45,60 @@ var ( titleStyle = lipgloss.NewStyle(). Foreground(lipgloss.Color("#FAFAFA")). B
 
 	dimStyle = lipgloss.NewStyle().
 			Foreground(lipgloss.Color("#444444"))
+
+	tabStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#626262")).
+			Padding(0, 1)
+
+	tabActiveStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#FAFAFA")).
+			Background(lipgloss.Color("#7D56F4")).
+			Padding(0, 1).
+			Bold(true)
 )
 
 func (m model) View() string {
 	if m.loading {
 		return m.loadingView()
 	}
+	if m.showConfirm {
+		// Confirmations float over whichever view asked for them
+		under := m
+		under.showConfirm = false
+		return dimStyle.Render(under.View()) + "\n" + m.floatingModal(m.confirmView(), m.width, 15)
+	}
+	if m.showLogs {
+		return m.logsView()
+	}
+	if m.showInspector {
+		return m.inspectorView()
+	}
+	if m.showTimers {
+		return m.timersView()
+	}
+	if m.showAudit {
+		return m.auditView()
+	}
+	if m.showProcesses {
+		return m.processesView()
+	}
+	if m.showUnitFiles {
+		return m.unitFilesView()
+	}
+	if m.showDeps {
+		return m.dependenciesView()
+	}
+	if m.showGroups {
+		return m.groupsView()
+	}
 
 	main := m.mainView()
 
 	// Get terminal size for centering
 	w, h := 80, 25
 	if m.allServices.Width() > 0 && m.allServices.Height() > 0 {
-		w = m.allServices.Width()*2 + 8 // 2 panes + padding
-		h = m.allServices.Height() + 10 // add for title/help
+		w = (m.allServices.Width() + 4) * m.paneCount() // panes + padding
+		h = m.allServices.Height() + 10                 // add for title/help
 	}
 
 	if m.showHelp {
@@ -70,9 +113,28 @@ func (m model) View() string {
 	if m.showMenu {
 		return dimStyle.Render(main) + "\n" + m.floatingModal(m.menuView(), w, h)
 	}
+	if m.showKillPicker {
+		title := fmt.Sprintf("📡 Send signal to every process of %s", m.selectedService.name)
+		return dimStyle.Render(main) + "\n" + m.floatingModal(signalPicker(title, m.killChoice), w, h)
+	}
 	if m.showDescription {
 		return dimStyle.Render(main) + "\n" + m.floatingModal(m.descriptionView(), w, h)
 	}
+	if m.showWizard {
+		return dimStyle.Render(main) + "\n" + m.floatingModal(m.wizardView(), w, h)
+	}
+	if m.showOverride {
+		return dimStyle.Render(main) + "\n" + m.floatingModal(m.overrideView(), w, h)
+	}
+	if m.showBulkReport {
+		return dimStyle.Render(main) + "\n" + m.floatingModal(m.bulkReportView(), w, h)
+	}
+	if m.showTagEditor {
+		return dimStyle.Render(main) + "\n" + m.floatingModal(m.tagEditorView(), w, h)
+	}
+	if m.showTagFilter {
+		return dimStyle.Render(main) + "\n" + m.floatingModal(m.tagFilterView(), w, h)
+	}
 
 	return main
 }
@@ -99,9 +161,14 @@ func (m model) loadingView() string {
 }
 
 func (m model) searchView() string {
-	windowName := "All Services"
-	if m.focused == 1 {
-		windowName = "Running Services"
+	windowName := "All " + m.currentUnitType().plural
+	switch m.focused {
+	case paneRunning:
+		windowName = strings.TrimPrefix(m.runningServices.Title, "🟢 ")
+	case paneFailed:
+		windowName = strings.TrimPrefix(m.failedServices.Title, "🔴 ")
+	case paneFavorites:
+		windowName = "Favorites"
 	}
 
 	searchBox := searchStyle.Render(
@@ -131,13 +198,64 @@ Navigation:
   s                  Search services
   r                  Reload UI/services
   U                  View/Edit service description
+  l                  View journal logs of the selected service
+  i                  Inspect all unit properties (/ to search)
+  T                  Timers dashboard (run now, enable/disable, logs)
+  A                  Action history from the audit log, filterable by unit
+                     and action (lazysys -audit prints it)
+  D                  Dependency tree of the selected unit (m: mode,
+                     Enter: expand, i/l/a: inspect, logs, actions)
+  c                  Unit file and drop-ins like systemctl cat (d: diff
+                     against the vendor file like systemd-delta)
+  E                  Edit /etc/systemd/system/<unit>.d/override.conf in
+                     $EDITOR or in place; verified before daemon-reload
+  Z                  Revert the last override edit
+  N                  New service wizard: writes the unit to
+                     /etc/systemd/system, reloads, optionally enables/starts
+  x                  Run a command as a transient unit with systemd-run
+                     (service or scope, MemoryMax, CPUQuota, OnCalendar)
+  o                  Sort the running pane by name, memory, CPU, tasks or IO
+  p                  Processes of the unit's control group with CPU/RSS
+                     (s: signal a process, S: signal the whole unit)
+  g                  Edit the tags of the selected unit (e.g. web db)
+  #                  Only list units with a tag (empty: show all)
+  G                  Units grouped by tag with running/failed counts
+                     (Enter: fold a section, a: actions, t: tags)
+  *                  Star/unstar the selected unit; starred units get a
+                     Favorites pane, focused on startup
+  F                  Toggle the failed units pane
   ?                  Toggle this help
   P                  Show about/coffee info
   q / Esc / Ctrl+C   Quit/close window
 
-Service Actions:
-  All Services:      1=Start, 2=Restart, 3=Stop, 4=Disable, 5=Enable
-  Running Services:  1=Stop, 2=Restart, 3=Disable
+Unit Types:
+  [ / ]              Switch between services, timers, sockets, mounts,
+                     automounts, paths, targets and slices
+
+Failed Units (while the failed pane is shown):
+  R                  Restart every failed unit and report each outcome
+  C                  Reset the failed state of all units
+
+Service Actions (number keys pick an entry of the action menu, 0 the tenth):
+  All Services:      Start, Restart, Stop, Try restart, Reload or restart,
+                     Reload, Kill…, Reset failed state, Disable/Enable, Mask
+  Running Services:  Stop, Restart, Reload, Kill…, Disable
+  Failed Units:      Restart, Reset failed state, Stop
+  Mounts offer Mount/Remount/Unmount, targets and slices Start/Stop, and
+  targets Isolate; entries follow the unit's CanStart, CanStop, CanReload and
+  CanIsolate, Kill… opens a signal picker for every process of the unit
+  Enable/Disable follow the unit file state; masked units only offer Unmask
+  Services also offer Schedule…, creating a paired .timer whose OnCalendar
+  is checked with systemd-analyze calendar before it is written
+
+Safety:
+  Stop, restart, disable, mask, kill and isolate ask y/n first (-confirm)
+  Protected units (-protect, default sshd, systemd-*, dbus) need their name
+  typed before those actions; -readonly disables every change to the system
+
+Unit File States:
+  🔒 disabled  📌 static  🚫 masked  🔀 indirect  🧩 generated
+  🔖 alias  🔗 linked  ⏳ enabled-runtime  💨 transient  ❗ bad
 `
 	return modalStyle.Render(help)
 }
@@ -170,36 +288,62 @@ func (m model) mainView() string {
 	// Title
 	s += titleStyle.Render("🔧 LazySys Service Manager") + "\n\n"
 
+	// Unit type tabs
+	var tabs []string
+	for i, t := range unitTypes {
+		if i == m.unitType {
+			tabs = append(tabs, tabActiveStyle.Render(t.plural))
+		} else {
+			tabs = append(tabs, tabStyle.Render(t.plural))
+		}
+	}
+	s += lipgloss.JoinHorizontal(lipgloss.Top, tabs...) + "\n\n"
+
 	// Service counts
 	allCount := len(m.allServices.Items())
 	runningCount := len(m.runningServices.Items())
-	s += fmt.Sprintf("📊 Total Services: %d | 🟢 Running: %d\n\n", allCount, runningCount)
-
-	// Lists
-	allServicesView := m.allServices.View()
-	runningServicesView := m.runningServices.View()
+	runningLabel := "Running"
+	if m.unitType != 0 {
+		runningLabel = "Active"
+	}
+	s += fmt.Sprintf("📊 Total %s: %d | 🟢 %s: %d | 🔴 Failed units: %d", m.currentUnitType().plural, allCount, runningLabel, runningCount, len(m.failedServices.Items()))
+	if m.safety.readOnly {
+		s += " | 🔒 Read-only"
+	}
+	s += "\n\n"
 
-	// Apply focus styling
-	if m.focused == 0 {
-		allServicesView = focusedStyle.Render(allServicesView)
-		runningServicesView = unfocusedStyle.Render(runningServicesView)
-	} else {
-		allServicesView = unfocusedStyle.Render(allServicesView)
-		runningServicesView = focusedStyle.Render(runningServicesView)
+	// Lists, with focus styling
+	var views []string
+	for i, pane := range m.visiblePanes() {
+		if i > 0 {
+			views = append(views, "  ")
+		}
+		l := m.allServices
+		switch pane {
+		case paneRunning:
+			l = m.runningServices
+		case paneFailed:
+			l = m.failedServices
+		case paneFavorites:
+			l = m.favoriteServices
+		}
+		if pane == m.focused {
+			views = append(views, focusedStyle.Render(l.View()))
+		} else {
+			views = append(views, unfocusedStyle.Render(l.View()))
+		}
 	}
 
 	// Split layout
-	lists := lipgloss.JoinHorizontal(
-		lipgloss.Top,
-		allServicesView,
-		"  ",
-		runningServicesView,
-	)
+	lists := lipgloss.JoinHorizontal(lipgloss.Top, views...)
 
 	s += lists + "\n\n"
 
 	// Help bar
-	helpText := "H/L: Navigate | j/k: Scroll | Enter: Action | s: Search | r: Reload || U: Show services info | ?: Help | P: About | q: Quit"
+	helpText := "H/L: Navigate | j/k: Scroll | Enter: Action | s: Search | r: Reload || [/]: Unit type | U: Show services info | l: Logs | i: Inspect | T: Timers | A: History | c: Unit file | E: Edit override | Z: Revert override | N: New service | x: Run transient | o: Sort running | D: Dependencies | p: Processes | g: Tags | #: Tag filter | G: Groups | *: Star | F: Failed | ?: Help | P: About | q: Quit"
+	if m.showFailed {
+		helpText += " || R: Restart all failed | C: Reset all failed"
+	}
 	s += helpStyle.Render(helpText)
 
 	// Message
@@ -212,23 +356,19 @@ func (m model) mainView() string {
 
 func (m model) menuView() string {
 	var menuItems []string
-	var title string
-
-	if m.focused == 0 {
-		title = fmt.Sprintf("🔧 Service: %s", m.selectedService.name)
-		menuItems = []string{
-			"1. Start",
-			"2. Restart",
-			"3. Stop",
-			"4. Disable",
-			"5. Enable",
-		}
-	} else {
-		title = fmt.Sprintf("🔧 Service: %s", m.selectedService.name)
-		menuItems = []string{
-			"1. Stop",
-			"2. Restart",
-			"3. Disable",
+	title := fmt.Sprintf("🔧 Service: %s", m.selectedService.name)
+	if m.selectedService.enabled != "" {
+		title += fmt.Sprintf(" (%s)", m.selectedService.enabled)
+	}
+
+	for i, action := range m.menuActions() {
+		switch {
+		case i < 9:
+			menuItems = append(menuItems, fmt.Sprintf("%d. %s", i+1, action.label))
+		case i == 9:
+			menuItems = append(menuItems, fmt.Sprintf("0. %s", action.label))
+		default:
+			menuItems = append(menuItems, "   "+action.label)
 		}
 	}
 
@@ -0,0 +1,246 @@
+package main
+
+import (
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// unitChange describes a unit whose state differs from what the lists show.
+type unitChange struct {
+	service service
+	removed bool
+}
+
+type unitsChangedMsg struct {
+	changes []unitChange
+}
+
+// unitWatcher is implemented by backends that can push unit state changes
+// themselves. Other backends are polled and diffed. Watching ends when done
+// is closed, which also closes the returned channel.
+type unitWatcher interface {
+	WatchUnits(done <-chan struct{}) (<-chan []unitChange, error)
+}
+
+// loadedUnitLister is implemented by backends that can list the loaded units
+// without the unit files, which is all polling needs: ListUnits costs the
+// systemctl backend a second fork per poll.
+type loadedUnitLister interface {
+	ListLoadedUnits() ([]service, error)
+}
+
+const pollInterval = 5 * time.Second
+
+type unitWatchStartedMsg struct {
+	changes <-chan []unitChange
+}
+
+// watchUnits starts streaming unit changes from the manager until done is
+// closed, preferring its own notifications over polling.
+func watchUnits(manager ServiceManager, done <-chan struct{}) tea.Cmd {
+	return func() tea.Msg {
+		if w, ok := manager.(unitWatcher); ok {
+			if changes, err := w.WatchUnits(done); err == nil {
+				return unitWatchStartedMsg{changes: changes}
+			}
+		}
+		return unitWatchStartedMsg{changes: pollUnits(manager, pollInterval, done)}
+	}
+}
+
+// stopWatchingUnits ends the watch Init started.
+func (m model) stopWatchingUnits() {
+	close(m.stopWatching)
+}
+
+// unitSnapshot is the state pollUnits diffs against, keyed by unit name.
+type unitSnapshot map[string]unitChange
+
+func takeUnitSnapshot(manager ServiceManager) (unitSnapshot, error) {
+	var all []service
+	var err error
+	if l, ok := manager.(loadedUnitLister); ok {
+		all, err = l.ListLoadedUnits()
+	} else {
+		all, err = manager.ListUnits("")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := make(unitSnapshot, len(all))
+	for _, s := range all {
+		snapshot[s.name] = unitChange{service: s}
+	}
+	return snapshot, nil
+}
+
+// diff returns the changes needed to turn old into s.
+func (s unitSnapshot) diff(old unitSnapshot) []unitChange {
+	var changes []unitChange
+	for name, c := range s {
+		if prev, ok := old[name]; !ok || prev != c {
+			changes = append(changes, c)
+		}
+	}
+	for name, c := range old {
+		if _, ok := s[name]; !ok {
+			changes = append(changes, unitChange{service: c.service, removed: true})
+		}
+	}
+	return changes
+}
+
+// pollUnits periodically lists the units and reports what changed since the
+// previous listing, the first one taken before it returns.
+func pollUnits(manager ServiceManager, interval time.Duration, done <-chan struct{}) <-chan []unitChange {
+	out := make(chan []unitChange)
+	previous, _ := takeUnitSnapshot(manager)
+	go func() {
+		defer close(out)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+			current, err := takeUnitSnapshot(manager)
+			if err != nil {
+				continue
+			}
+			if changes := current.diff(previous); len(changes) > 0 {
+				select {
+				case out <- changes:
+				case <-done:
+					return
+				}
+			}
+			previous = current
+		}
+	}()
+	return out
+}
+
+func waitForUnitChanges(changes <-chan []unitChange) tea.Cmd {
+	return func() tea.Msg {
+		c, ok := <-changes
+		if !ok {
+			return nil
+		}
+		return unitsChangedMsg{changes: c}
+	}
+}
+
+// applyUnitChanges patches the affected list items in place, keeping each
+// list's selection on the same unit. It reports whether a unit entered the
+// failed state, whose result the change does not carry.
+func (m *model) applyUnitChanges(changes []unitChange) bool {
+	newlyFailed := false
+	for _, c := range changes {
+		if s, ok := findListed(&m.allServices, c.service.name); ok && c.removed && hasUnitFile(s) {
+			// systemd unloads inactive units it no longer needs, yet they
+			// stay installed and listed like after a reload
+			s.active, s.sub = "inactive", "dead"
+			c = unitChange{service: s}
+		}
+		failed := !c.removed && c.service.active == "failed"
+		if failed && !listContains(&m.failedServices, c.service.name) {
+			newlyFailed = true
+		}
+		patchServiceList(&m.failedServices, c.service, failed)
+		if m.favorites[c.service.name] {
+			favorite := c.service
+			if c.removed {
+				// Still starred, shown like loadFavorites shows unloaded units
+				favorite = service{name: favorite.name, description: "Not loaded", loaded: "not-found", active: "inactive"}
+			}
+			patchServiceList(&m.favoriteServices, favorite, true)
+		}
+
+		if unitTypeOf(c.service.name) != m.currentUnitType().name {
+			continue
+		}
+		patchServiceList(&m.allServices, c.service, !c.removed)
+		patchServiceList(&m.runningServices, c.service, !c.removed && isRunning(c.service))
+	}
+	return newlyFailed
+}
+
+func listContains(l *list.Model, name string) bool {
+	_, ok := findListed(l, name)
+	return ok
+}
+
+func findListed(l *list.Model, name string) (service, bool) {
+	for _, item := range l.Items() {
+		if s, ok := item.(service); ok && s.name == name {
+			return s, true
+		}
+	}
+	return service{}, false
+}
+
+// hasUnitFile reports whether s was listed with a unit file of its own, which
+// mergeUnitFileStates lists whether the unit is loaded or not. Instances take
+// their state from the template and transient units lose their file.
+func hasUnitFile(s service) bool {
+	return s.enabled != "" && s.enabled != "transient" && !strings.Contains(s.name, "@")
+}
+
+func patchServiceList(l *list.Model, s service, present bool) {
+	selected := ""
+	if item, ok := l.SelectedItem().(service); ok {
+		selected = item.name
+	}
+
+	index, insertAt := -1, len(l.Items())
+	for i, item := range l.Items() {
+		existing, ok := item.(service)
+		if !ok {
+			continue
+		}
+		if existing.name == s.name {
+			index = i
+			break
+		}
+		if insertAt == len(l.Items()) && existing.name > s.name {
+			insertAt = i
+		}
+	}
+
+	switch {
+	case present && index >= 0:
+		// Changes only carry state, keep the columns from the last reload
+		previous := l.Items()[index].(service)
+		if s.details == "" {
+			s.details = previous.details
+		}
+		if s.usage == "" {
+			s.usage = previous.usage
+		}
+		if s.enabled == "" {
+			s.enabled = previous.enabled
+		}
+		l.SetItem(index, s)
+		return
+	case present:
+		l.InsertItem(insertAt, s)
+	case index >= 0:
+		l.RemoveItem(index)
+	default:
+		return
+	}
+
+	for i, item := range l.Items() {
+		if existing, ok := item.(service); ok && existing.name == selected {
+			l.Select(i)
+			break
+		}
+	}
+}
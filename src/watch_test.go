@@ -0,0 +1,134 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/bubbles/list"
+)
+
+func TestApplyUnitChangesFavorites(t *testing.T) {
+	newList := func(services ...service) list.Model {
+		return list.New(toListItems(services), list.NewDefaultDelegate(), 0, 0)
+	}
+	nginx := service{name: "nginx.service", loaded: "loaded", active: "active", sub: "running"}
+	m := model{
+		allServices:      newList(nginx),
+		runningServices:  newList(nginx),
+		failedServices:   newList(),
+		favoriteServices: newList(nginx),
+		favorites:        map[string]bool{"nginx.service": true},
+	}
+
+	stopped := nginx
+	stopped.active, stopped.sub = "inactive", "dead"
+	m.applyUnitChanges([]unitChange{{service: stopped}})
+	if got := m.favoriteServices.Items()[0].(service); got.active != "inactive" {
+		t.Errorf("after stop: favorite active = %q, want inactive", got.active)
+	}
+	if n := len(m.runningServices.Items()); n != 0 {
+		t.Errorf("after stop: %d running, want 0", n)
+	}
+
+	m.applyUnitChanges([]unitChange{{service: stopped, removed: true}})
+	if n := len(m.favoriteServices.Items()); n != 1 {
+		t.Fatalf("after removal: %d favorites, want the starred unit kept", n)
+	}
+	if got := m.favoriteServices.Items()[0].(service); got.loaded != "not-found" || got.active != "inactive" {
+		t.Errorf("after removal: favorite loaded=%q active=%q, want not-found inactive", got.loaded, got.active)
+	}
+	if n := len(m.allServices.Items()); n != 0 {
+		t.Errorf("after removal: %d units listed, want 0", n)
+	}
+}
+
+func TestApplyUnitChangesRemoved(t *testing.T) {
+	newList := func(services ...service) list.Model {
+		return list.New(toListItems(services), list.NewDefaultDelegate(), 0, 0)
+	}
+	cups := service{name: "cups.service", description: "CUPS Scheduler", loaded: "loaded", active: "active", sub: "running", enabled: "disabled"}
+	getty := service{name: "getty@tty2.service", loaded: "loaded", active: "active", sub: "running", enabled: "enabled"}
+	transient := service{name: "run-u42.service", loaded: "loaded", active: "active", sub: "running", enabled: "transient"}
+	m := model{
+		allServices:      newList(cups, getty, transient),
+		runningServices:  newList(cups, getty, transient),
+		failedServices:   newList(),
+		favoriteServices: newList(),
+	}
+
+	m.applyUnitChanges([]unitChange{
+		{service: service{name: cups.name}, removed: true},
+		{service: service{name: getty.name}, removed: true},
+		{service: service{name: transient.name}, removed: true},
+	})
+
+	all := m.allServices.Items()
+	if len(all) != 1 {
+		t.Fatalf("listed %v, want only the unit with a unit file kept", all)
+	}
+	want := cups
+	want.active, want.sub = "inactive", "dead"
+	if got := all[0].(service); got != want {
+		t.Errorf("kept %+v, want %+v", got, want)
+	}
+	if n := len(m.runningServices.Items()); n != 0 {
+		t.Errorf("%d running after removal, want 0", n)
+	}
+}
+
+func TestPatchServiceListKeepsColumns(t *testing.T) {
+	nginx := service{name: "nginx.service", active: "active", sub: "running", enabled: "enabled", details: "pid 812", usage: "12.0M"}
+	l := list.New(toListItems([]service{nginx}), list.NewDefaultDelegate(), 0, 0)
+
+	// A polled or signalled change without unit file state, columns or usage
+	patchServiceList(&l, service{name: "nginx.service", active: "active", sub: "reloading"}, true)
+
+	want := nginx
+	want.sub = "reloading"
+	if got := l.Items()[0].(service); got != want {
+		t.Errorf("patched to %+v, want %+v", got, want)
+	}
+}
+
+// loadedOnlyManager fails the test when polling lists the unit files.
+type loadedOnlyManager struct {
+	*memoryManager
+	t *testing.T
+}
+
+func (m loadedOnlyManager) ListUnits(unitType string) ([]service, error) {
+	m.t.Error("polling listed the unit files too")
+	return m.memoryManager.ListUnits(unitType)
+}
+
+func (m loadedOnlyManager) ListLoadedUnits() ([]service, error) {
+	return m.memoryManager.ListUnits("")
+}
+
+func TestPollUnits(t *testing.T) {
+	manager := loadedOnlyManager{newMemoryManager(), t}
+	done := make(chan struct{})
+	changes := pollUnits(manager, 10*time.Millisecond, done)
+
+	if err := manager.Stop("cron.service"); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case c := <-changes:
+		if len(c) != 1 || c[0].service.name != "cron.service" || c[0].service.active != "inactive" {
+			t.Errorf("changes %+v, want cron.service inactive", c)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no change polled")
+	}
+
+	close(done)
+	select {
+	case _, ok := <-changes:
+		if ok {
+			t.Error("change polled after done was closed")
+		}
+	case <-time.After(time.Second):
+		t.Error("polling kept going after done was closed")
+	}
+}
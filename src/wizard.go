@@ -0,0 +1,402 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// wizardField is one step of a wizard. Fields with choices are picked with
+// ←/→ instead of typed.
+type wizardField struct {
+	label       string
+	placeholder string
+	choices     []string
+	required    bool
+}
+
+// wizard is a multi-step form that ends with a preview of the unit file it
+// generates. render turns the answers into the unit's name and content, and
+// the optional check validates them before the unit can be written. Wizards
+// with launch run a command instead, previewed by render.
+type wizard struct {
+	title    string
+	fields   []wizardField
+	values   []string
+	step     int // len(fields) is the preview
+	input    textinput.Model
+	render   func(values []string) (unit, content string, err error)
+	check    func(values []string) tea.Cmd
+	launch   func(values []string) tea.Cmd
+	checking bool
+	checked  string // output of the check, shown with the preview
+	checkErr error
+	enable   bool
+	start    bool
+	writing  bool
+	err      error
+}
+
+type wizardCheckedMsg struct {
+	output string
+	err    error
+}
+
+type unitCreatedMsg struct {
+	unit string
+	path string
+	err  error
+}
+
+// hardeningPresets are the sandboxing options the service wizard can add,
+// see systemd.exec(5).
+var hardeningPresets = []struct {
+	name    string
+	options []string
+}{
+	{"none", nil},
+	{"basic", []string{"NoNewPrivileges=yes", "PrivateTmp=yes", "ProtectSystem=full", "ProtectHome=read-only"}},
+	{"strict", []string{
+		"NoNewPrivileges=yes", "PrivateTmp=yes", "PrivateDevices=yes", "ProtectSystem=strict", "ProtectHome=yes",
+		"ProtectKernelTunables=yes", "ProtectKernelModules=yes", "ProtectControlGroups=yes",
+		"RestrictSUIDSGID=yes", "LockPersonality=yes",
+	}},
+}
+
+func newServiceWizard() wizard {
+	var presets []string
+	for _, p := range hardeningPresets {
+		presets = append(presets, p.name)
+	}
+	return newWizard("➕ New service", []wizardField{
+		{label: "Unit name", placeholder: "myapp (.service is added)", required: true},
+		{label: "Description", placeholder: "My application"},
+		{label: "ExecStart", placeholder: "/usr/local/bin/myapp --serve", required: true},
+		{label: "User", placeholder: "root when empty"},
+		{label: "WorkingDirectory", placeholder: "/srv/myapp"},
+		{label: "Environment", placeholder: "KEY=value OTHER=value"},
+		{label: "Restart", choices: []string{"on-failure", "always", "on-abnormal", "no"}},
+		{label: "WantedBy", choices: []string{"multi-user.target", "graphical.target", "default.target"}},
+		{label: "Hardening", choices: presets},
+	}, renderServiceUnit)
+}
+
+func newWizard(title string, fields []wizardField, render func([]string) (string, string, error)) wizard {
+	input := textinput.New()
+	input.CharLimit = 256
+	input.Width = 50
+	w := wizard{title: title, fields: fields, values: make([]string, len(fields)), input: input, render: render}
+	for i, f := range fields {
+		if len(f.choices) > 0 {
+			w.values[i] = f.choices[0]
+		}
+	}
+	w.setStep(0)
+	return w
+}
+
+// unitName adds suffix to name unless it already carries it, rejecting
+// names that are not plain file names.
+func unitName(name, suffix string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || strings.ContainsAny(name, "/ ") {
+		return "", fmt.Errorf("invalid unit name %q", name)
+	}
+	if !strings.HasSuffix(name, "."+suffix) {
+		name += "." + suffix
+	}
+	return name, nil
+}
+
+func renderServiceUnit(v []string) (string, string, error) {
+	name, err := unitName(v[0], "service")
+	if err != nil {
+		return "", "", err
+	}
+	description := v[1]
+	if description == "" {
+		description = strings.TrimSuffix(name, ".service")
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "[Unit]\nDescription=%s\nAfter=network.target\n\n", description)
+	fmt.Fprintf(&b, "[Service]\nType=simple\nExecStart=%s\n", v[2])
+	if v[3] != "" {
+		fmt.Fprintf(&b, "User=%s\n", v[3])
+	}
+	if v[4] != "" {
+		fmt.Fprintf(&b, "WorkingDirectory=%s\n", v[4])
+	}
+	if v[5] != "" {
+		fmt.Fprintf(&b, "Environment=%s\n", v[5])
+	}
+	fmt.Fprintf(&b, "Restart=%s\n", v[6])
+	for _, p := range hardeningPresets {
+		if p.name == v[8] && len(p.options) > 0 {
+			b.WriteString("\n" + strings.Join(p.options, "\n") + "\n")
+		}
+	}
+	fmt.Fprintf(&b, "\n[Install]\nWantedBy=%s\n", v[7])
+	return name, b.String(), nil
+}
+
+// createUnitFile writes a new unit file, reloads the daemon so systemd
+// knows about it, then enables and starts it if asked to. Existing units
+// are never overwritten.
+func createUnitFile(manager ServiceManager, unit, content string, enable, start bool) tea.Cmd {
+	return func() tea.Msg {
+		path := filepath.Join(systemUnitDir, unit)
+		_, err := auditAction(manager, unit, "create", func() (string, error) {
+			f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+			if errors.Is(err, os.ErrExist) {
+				return "", fmt.Errorf("%s already exists", path)
+			}
+			if err != nil {
+				return "", err
+			}
+			_, err = f.WriteString(content)
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				os.Remove(path)
+				return "", err
+			}
+			return "wrote " + path, nil
+		})
+		if err != nil {
+			return unitCreatedMsg{unit: unit, path: path, err: err}
+		}
+
+		if err := manager.DaemonReload(); err != nil {
+			return unitCreatedMsg{unit: unit, path: path, err: fmt.Errorf("daemon-reload failed: %v", err)}
+		}
+		if enable {
+			if err := manager.Enable(unit); err != nil {
+				return unitCreatedMsg{unit: unit, path: path, err: fmt.Errorf("created, but enabling failed: %v", err)}
+			}
+		}
+		if start {
+			if err := manager.Start(unit); err != nil {
+				return unitCreatedMsg{unit: unit, path: path, err: fmt.Errorf("created, but starting failed: %v", err)}
+			}
+		}
+		return unitCreatedMsg{unit: unit, path: path}
+	}
+}
+
+// setStep moves to step, loading its answer into the input.
+func (w *wizard) setStep(step int) {
+	w.step = step
+	w.err = nil
+	if step >= len(w.fields) {
+		w.input.Blur()
+		return
+	}
+	f := w.fields[step]
+	w.input.Placeholder = f.placeholder
+	w.input.SetValue(w.values[step])
+	w.input.CursorEnd()
+	if len(f.choices) == 0 {
+		w.input.Focus()
+	} else {
+		w.input.Blur()
+	}
+}
+
+// cycleChoice moves the current choice field by delta.
+func (w *wizard) cycleChoice(delta int) {
+	choices := w.fields[w.step].choices
+	current := 0
+	for i, c := range choices {
+		if c == w.values[w.step] {
+			current = i
+		}
+	}
+	w.values[w.step] = choices[(current+delta+len(choices))%len(choices)]
+}
+
+func (w wizard) preview() (string, string, error) {
+	return w.render(w.values)
+}
+
+// enterPreview validates the answers once the last step is done.
+func (w *wizard) enterPreview() tea.Cmd {
+	if w.step != len(w.fields) {
+		return nil
+	}
+	if _, _, err := w.preview(); err != nil {
+		w.err = err
+		return nil
+	}
+	if w.check == nil {
+		return nil
+	}
+	w.checking, w.checked, w.checkErr = true, "", nil
+	return w.check(w.values)
+}
+
+func (m model) updateWizard(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	w := &m.wizard
+	if w.writing {
+		return m, nil
+	}
+	switch msg.String() {
+	case "esc":
+		m.showWizard = false
+		return m, nil
+	case "shift+tab":
+		if w.step > 0 {
+			w.setStep(w.step - 1)
+		}
+		return m, nil
+	}
+
+	if w.step == len(w.fields) {
+		switch msg.String() {
+		case "e":
+			w.enable = !w.enable && w.launch == nil
+		case "s":
+			w.start = !w.start && w.launch == nil
+		case "enter":
+			if w.checking {
+				return m, nil
+			}
+			if w.launch != nil {
+				unit, _, err := w.preview()
+				if err != nil {
+					w.err = err
+					return m, nil
+				}
+				w.writing = true
+				return m.perform(pendingAction{
+					action: "run-transient",
+					label:  "Run " + unit,
+					units:  []string{unit},
+					run:    w.launch(w.values),
+				})
+			}
+			unit, content, err := w.preview()
+			if err == nil {
+				err = w.checkErr
+			}
+			if err != nil {
+				w.err = err
+				return m, nil
+			}
+			w.writing = true
+			return m.perform(pendingAction{
+				action: "create",
+				label:  "Create " + unit,
+				units:  []string{unit},
+				run:    createUnitFile(m.manager, unit, content, w.enable, w.start),
+			})
+		}
+		return m, nil
+	}
+
+	field := w.fields[w.step]
+	if len(field.choices) > 0 {
+		switch msg.String() {
+		case "left", "h":
+			w.cycleChoice(-1)
+		case "right", "l", "tab", " ":
+			w.cycleChoice(1)
+		case "enter":
+			w.setStep(w.step + 1)
+			return m, w.enterPreview()
+		}
+		return m, nil
+	}
+
+	if msg.String() == "enter" {
+		value := strings.TrimSpace(w.input.Value())
+		if field.required && value == "" {
+			w.err = fmt.Errorf("%s is required", field.label)
+			return m, nil
+		}
+		w.values[w.step] = value
+		w.setStep(w.step + 1)
+		return m, w.enterPreview()
+	}
+
+	var cmd tea.Cmd
+	w.input, cmd = w.input.Update(msg)
+	return m, cmd
+}
+
+func (m model) wizardView() string {
+	w := m.wizard
+	content := fmt.Sprintf("%s — step %d of %d\n\n", w.title, w.step+1, len(w.fields)+1)
+
+	if w.step < len(w.fields) {
+		field := w.fields[w.step]
+		content += field.label
+		if field.required {
+			content += " *"
+		}
+		content += "\n"
+		if len(field.choices) > 0 {
+			var choices []string
+			for _, c := range field.choices {
+				if c == w.values[w.step] {
+					choices = append(choices, tabActiveStyle.Render(c))
+				} else {
+					choices = append(choices, tabStyle.Render(c))
+				}
+			}
+			content += strings.Join(choices, " ") + "\n\n←/→: Choose | Enter: Next"
+		} else {
+			content += w.input.View() + "\n\nEnter: Next"
+		}
+		content += " | Shift+Tab: Back | Esc: Cancel"
+	} else {
+		unit, unitContent, err := w.preview()
+		switch {
+		case err != nil:
+		case w.launch != nil:
+			content += unitPathStyle.Render("# "+unit) + "\n$ " + unitContent + "\n"
+		default:
+			content += unitPathStyle.Render("# "+filepath.Join(systemUnitDir, unit)) + "\n"
+			for _, line := range splitLines(unitContent) {
+				content += highlightUnitLine(line) + "\n"
+			}
+		}
+		check := func(on bool) string {
+			if on {
+				return "[x]"
+			}
+			return "[ ]"
+		}
+		switch {
+		case w.checking:
+			content += "\n" + helpStyle.Render("Checking...") + "\n"
+		case w.checkErr != nil:
+			content += "\n" + diffRemoveStyle.Render(w.checked) + "\n"
+		case w.checked != "":
+			content += "\n" + helpStyle.Render(w.checked) + "\n"
+		}
+		if w.launch != nil {
+			if w.writing {
+				content += "\nStarting..."
+			}
+			content += "\nEnter: Run | Shift+Tab: Back | Esc: Cancel"
+		} else {
+			content += fmt.Sprintf("\n%s e: Enable   %s s: Start now\n", check(w.enable), check(w.start))
+			if w.writing {
+				content += "\nWriting unit file..."
+			}
+			content += "\nEnter: Write & daemon-reload | Shift+Tab: Back | Esc: Cancel"
+		}
+	}
+
+	if w.err != nil {
+		content += "\n\n" + diffRemoveStyle.Render(fmt.Sprintf("❌ %v", w.err))
+	}
+	return modalStyle.Render(content)
+}